@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"theo303/neon-pricer/internal/usecases"
+
+	"github.com/spf13/cobra"
+)
+
+// annotateCmd represents the annotate command
+var annotateCmd = &cobra.Command{
+	Use:   "annotate <file.svg>",
+	Short: "Draw the measured strokes, their lengths and the skipped elements over a svg file.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output-file")
+		if err != nil {
+			return err
+		}
+		if output == "" {
+			output = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".annotated.svg"
+		}
+
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("reading design: %w", err)
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating annotated design: %w", err)
+		}
+		defer f.Close()
+		skipped, err := usecases.WriteAnnotations(f, args[0], raw, config)
+		if err != nil {
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("writing annotated design: %w", err)
+		}
+
+		res := annotateResult{fileResult: fileResult{OutputFile: output}, Skipped: []skippedElement{}}
+		for _, s := range skipped {
+			res.Skipped = append(res.Skipped, skippedElement{Group: s.Group, Element: s.Element, ID: s.ID, Reason: s.Reason})
+		}
+		return printResult(cmd, res)
+	},
+}
+
+type skippedElement struct {
+	Group   string `json:"group"`
+	Element string `json:"element"`
+	ID      string `json:"id"`
+	Reason  string `json:"reason"`
+}
+
+type annotateResult struct {
+	fileResult
+	Skipped []skippedElement `json:"skipped"`
+}
+
+func (r annotateResult) text(w io.Writer) error {
+	for _, s := range r.Skipped {
+		group := s.Group
+		if group == "" {
+			group = "-"
+		}
+		fmt.Fprintf(w, "skipped %s id=%q group=%s: %s\n", s.Element, s.ID, group, s.Reason)
+	}
+	return r.fileResult.text(w)
+}
+
+func (r annotateResult) rows() [][]string {
+	rows := [][]string{{"group", "element", "id", "reason"}}
+	for _, s := range r.Skipped {
+		rows = append(rows, []string{s.Group, s.Element, s.ID, s.Reason})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(annotateCmd)
+
+	annotateCmd.Flags().StringP("output-file", "o", "", "svg file to write, the svg file name ending with .annotated.svg if empty")
+}
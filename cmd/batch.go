@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+	"theo303/neon-pricer/internal/usecases"
+
+	"github.com/spf13/cobra"
+)
+
+// batchCmd represents the batch command
+var batchCmd = &cobra.Command{
+	Use:   "batch <dir|glob|file.svg|file.dxf>...",
+	Short: "Quote all the svg and dxf files of directories or globs.",
+	Long: `Quote all the svg and dxf files of directories or globs.
+Directories are searched recursively. Files are quoted concurrently, a broken
+file does not stop the batch, the errors are listed in the summary and the
+command fails at the end if any file failed.
+
+With --results-dir, the quote of each file is written as json in the directory,
+along with the summary as summary.json and summary.csv. With --pdf-dir, the
+customer quote of each file is written as pdf in that directory.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, plexi, quantity, err := quoteOptions(cmd)
+		if err != nil {
+			return err
+		}
+		workers, err := cmd.Flags().GetInt("workers")
+		if err != nil {
+			return err
+		}
+		resultsDir, err := cmd.Flags().GetString("results-dir")
+		if err != nil {
+			return err
+		}
+		pdfDir, err := cmd.Flags().GetString("pdf-dir")
+		if err != nil {
+			return err
+		}
+		save, err := cmd.Flags().GetBool("save")
+		if err != nil {
+			return err
+		}
+		if resultsDir != "" {
+			if err := os.MkdirAll(resultsDir, 0o755); err != nil {
+				return fmt.Errorf("creating results directory: %w", err)
+			}
+		}
+		if pdfDir != "" {
+			if err := os.MkdirAll(pdfDir, 0o755); err != nil {
+				return fmt.Errorf("creating pdf directory: %w", err)
+			}
+		}
+
+		paths, err := usecases.FindDesignFiles(args)
+		if err != nil {
+			return err
+		}
+		items := usecases.QuoteFiles(paths, workers, config, plexi, quantity, svg.Limits{})
+
+		res := batchResult{Files: make([]batchFile, 0, len(items))}
+		for _, item := range items {
+			if item.Err == nil && save {
+				item.Err = saveQuote(cmd, &item.Quote)
+			}
+			if item.Err == nil && resultsDir != "" {
+				item.Err = writeBatchResults(resultsDir, item)
+			}
+			if item.Err == nil && pdfDir != "" {
+				item.Err = writeBatchPDF(pdfDir, item)
+			}
+			file := batchFile{File: item.Path}
+			if item.Err != nil {
+				file.Error = item.Err.Error()
+				res.Failed++
+			} else {
+				file.QuoteID = item.Quote.ID
+				file.Hash = item.Quote.Hash
+				file.Total = item.Quote.Total
+				res.Total += item.Quote.Total
+			}
+			res.Files = append(res.Files, file)
+		}
+		res.Total = domain.Round(res.Total)
+
+		if resultsDir != "" {
+			for _, format := range []string{outputJSON, outputCSV} {
+				if err := writeBatchSummary(filepath.Join(resultsDir, "summary."+format), format, res); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := printResult(cmd, res); err != nil {
+			return err
+		}
+		if res.Failed > 0 {
+			return fmt.Errorf("%d of %d files failed", res.Failed, len(res.Files))
+		}
+		return nil
+	},
+}
+
+func saveQuote(cmd *cobra.Command, quote *domain.Quote) error {
+	quotes, err := requireQuoteStore(cmd)
+	if err != nil {
+		return err
+	}
+	if err := quotes.Save(quote); err != nil {
+		return fmt.Errorf("saving quote: %w", err)
+	}
+	return nil
+}
+
+// batchResultName returns the name of the files written for the item, after the path of its file
+// with its extension, the svg and dxf files of a same design being quoted apart.
+func batchResultName(item usecases.BatchItem) string {
+	name := filepath.ToSlash(filepath.Clean(item.Path))
+	return strings.NewReplacer("/", "_", ":", "_").Replace(strings.TrimPrefix(name, "/"))
+}
+
+// writeBatchResults writes the quote of the item as json in dir.
+func writeBatchResults(dir string, item usecases.BatchItem) error {
+	res, err := newQuoteResult(item.Quote)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, batchResultName(item)+".json"), raw, 0o644); err != nil {
+		return fmt.Errorf("writing result: %w", err)
+	}
+	return nil
+}
+
+// writeBatchPDF writes the customer quote of the item as pdf in dir.
+func writeBatchPDF(dir string, item usecases.BatchItem) error {
+	f, err := os.Create(filepath.Join(dir, batchResultName(item)+".pdf"))
+	if err != nil {
+		return fmt.Errorf("creating pdf: %w", err)
+	}
+	defer f.Close()
+	if err := usecases.WriteQuotePDF(f, item.Quote, item.Quote.Config.Branding); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func writeBatchSummary(path, format string, res batchResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating summary: %w", err)
+	}
+	defer f.Close()
+	if err := writeResult(f, format, res); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+type batchFile struct {
+	File    string  `json:"file"`
+	QuoteID string  `json:"quote_id,omitempty"`
+	Hash    string  `json:"hash,omitempty"`
+	Total   float64 `json:"total"`
+	Error   string  `json:"error,omitempty"`
+}
+
+type batchResult struct {
+	Files  []batchFile `json:"files"`
+	Total  float64     `json:"total"`
+	Failed int         `json:"failed"`
+}
+
+func (r batchResult) text(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tQUOTE\tTOTAL")
+	for _, f := range r.Files {
+		if f.Error != "" {
+			fmt.Fprintf(w, "%s\t\tfailed\n", f.File)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.2f\n", f.File, f.QuoteID, f.Total)
+	}
+	fmt.Fprintf(w, "total\t\t%.2f\n", r.Total)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if r.Failed == 0 {
+		return nil
+	}
+	fmt.Fprintln(out, "\nerrors:")
+	for _, f := range r.Files {
+		if f.Error != "" {
+			fmt.Fprintf(out, "%s: %s\n", f.File, f.Error)
+		}
+	}
+	return nil
+}
+
+func (r batchResult) rows() [][]string {
+	rows := [][]string{{"file", "quote_id", "hash", "total", "error"}}
+	for _, f := range r.Files {
+		rows = append(rows, []string{f.File, f.QuoteID, f.Hash, formatFloat(f.Total), f.Error})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+
+	addQuoteFlags(batchCmd)
+	batchCmd.Flags().IntP("workers", "j", runtime.NumCPU(), "number of files quoted at once")
+	batchCmd.Flags().String("results-dir", "", "directory where the quote of each file is written as json")
+	batchCmd.Flags().String("pdf-dir", "", "directory where the customer quote of each file is written as pdf")
+	batchCmd.Flags().Bool("save", false, "save the quotes in the quotes database")
+}
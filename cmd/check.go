@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+	"theo303/neon-pricer/internal/usecases"
+
+	"github.com/spf13/cobra"
+)
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:     "check <file.svg|file.dxf>",
+	Aliases: []string{"lint"},
+	Short:   "Check that a design can be built.",
+	Long: `Check that a design can be built.
+The strokes are checked against the minimum bend radius of their silicone,
+the minimum length of their LED strip and the minimum spacing of the design
+rules, and the design against the sheets of the plexi. The location of each
+issue is given in the coordinates of the design.
+
+The command fails if an issue of severity error is found.`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if err := backingOption(cmd, &config); err != nil {
+			return err
+		}
+		plexi, err := cmd.Flags().GetString("plexi")
+		if err != nil {
+			return err
+		}
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("reading design: %w", err)
+		}
+		issues, err := usecases.CheckDesign(args[0], raw, config, plexi, svg.Limits{})
+		if err != nil {
+			return err
+		}
+		if err := printResult(cmd, checkResult{Issues: issues}); err != nil {
+			return err
+		}
+
+		var errorCount int
+		for _, issue := range issues {
+			if issue.Severity == domain.SeverityError {
+				errorCount++
+			}
+		}
+		if errorCount > 0 {
+			return fmt.Errorf("design cannot be built: %d of %d issues are errors", errorCount, len(issues))
+		}
+		return nil
+	},
+}
+
+// checkResult is written as the issues of the json api.
+type checkResult struct {
+	Issues []domain.Issue `json:"issues"`
+}
+
+func (r checkResult) text(out io.Writer) error {
+	if len(r.Issues) == 0 {
+		fmt.Fprintln(out, "no issue found")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEVERITY\tRULE\tGROUP\tLOCATION\tMESSAGE")
+	for _, issue := range r.Issues {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.1f,%.1f\t%s\n",
+			issue.Severity, issue.Rule, issue.Group, issue.X, issue.Y, issue.Message)
+	}
+	return w.Flush()
+}
+
+func (r checkResult) rows() [][]string {
+	rows := [][]string{{"severity", "rule", "group", "x", "y", "value_mm", "limit_mm", "message"}}
+	for _, issue := range r.Issues {
+		rows = append(rows, []string{string(issue.Severity), issue.Rule, issue.Group,
+			formatFloat(issue.X), formatFloat(issue.Y), formatFloat(issue.Value), formatFloat(issue.Limit), issue.Message})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().String("plexi", "", "plexi used for the backing, the default plexi if empty")
+	addBackingFlag(checkCmd)
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"theo303/neon-pricer/internal/usecases"
+
+	"github.com/spf13/cobra"
+)
+
+// cutCmd represents the cut command
+var cutCmd = &cobra.Command{
+	Use:   "cut <file.svg|file.dxf>",
+	Short: "Export the cut file of the plexi backing of a svg or dxf file, with its mounting holes.",
+	Long: `Export the cut file of the plexi backing of a svg or dxf file, with its mounting holes.
+
+The DECOUPE group, or the backing generated for designs without one, is written in millimetres
+as a svg or a dxf R12, with a hole for a standoff near each of its corners and a hole under the
+feed point of each run of strokes for its cable. The format is the one of the output file
+extension unless --format is given.
+
+Arcs and curves are kept unless --flatten is given or the configuration says otherwise, the dxf
+keeping only circular arcs. Flattened curves stay within the tolerance of the configuration.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if err := backingOption(cmd, &config); err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output-file")
+		if err != nil {
+			return err
+		}
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		if format == "" {
+			format = usecases.CutFormatSVG
+			if ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(output), ".")); slices.Contains(usecases.CutFormats, ext) {
+				format = ext
+			}
+		}
+		if !slices.Contains(usecases.CutFormats, format) {
+			return fmt.Errorf("unknown cut file format %q, expected one of %s", format, strings.Join(usecases.CutFormats, ", "))
+		}
+		if output == "" {
+			output = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".cut." + format
+		}
+		if cmd.Flags().Changed("tolerance") {
+			if config.CutFile.ToleranceMm, err = cmd.Flags().GetFloat64("tolerance"); err != nil {
+				return err
+			}
+			if config.CutFile.ToleranceMm <= 0 {
+				return fmt.Errorf("tolerance must be positive, got %v", config.CutFile.ToleranceMm)
+			}
+		}
+		flatten, err := cmd.Flags().GetBool("flatten")
+		if err != nil {
+			return err
+		}
+		if flatten {
+			config.CutFile.KeepCurves = false
+		}
+
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("reading design: %w", err)
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating cut file: %w", err)
+		}
+		defer f.Close()
+		holes, err := usecases.WriteCutFile(f, args[0], raw, config, format)
+		if err != nil {
+			_ = os.Remove(output)
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("writing cut file: %w", err)
+		}
+
+		res := cutResult{fileResult: fileResult{OutputFile: output}, Holes: []cutHole{}}
+		toMm := 1000 / config.Scale
+		for _, h := range holes {
+			res.Holes = append(res.Holes, cutHole{
+				Kind:       h.Kind,
+				XMm:        h.Center.X * toMm,
+				YMm:        h.Center.Y * toMm,
+				DiameterMm: h.Diameter * toMm,
+			})
+		}
+		return printResult(cmd, res)
+	},
+}
+
+type cutHole struct {
+	Kind string `json:"kind"`
+	// XMm and YMm are the position of the hole in the design, in mm.
+	XMm        float64 `json:"x_mm"`
+	YMm        float64 `json:"y_mm"`
+	DiameterMm float64 `json:"diameter_mm"`
+}
+
+type cutResult struct {
+	fileResult
+	Holes []cutHole `json:"holes"`
+}
+
+func (r cutResult) text(w io.Writer) error {
+	counts := make(map[string]int)
+	for _, h := range r.Holes {
+		counts[h.Kind]++
+	}
+	fmt.Fprintf(w, "%d holes: %d for standoffs, %d for cables\n",
+		len(r.Holes), counts[usecases.HoleStandoff], counts[usecases.HoleCable])
+	return r.fileResult.text(w)
+}
+
+func (r cutResult) rows() [][]string {
+	rows := [][]string{{"kind", "x_mm", "y_mm", "diameter_mm"}}
+	for _, h := range r.Holes {
+		rows = append(rows, []string{h.Kind, formatFloat(h.XMm), formatFloat(h.YMm), formatFloat(h.DiameterMm)})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(cutCmd)
+
+	cutCmd.Flags().StringP("output-file", "o", "", "file to write, the svg file name ending with .cut and the format extension if empty")
+	cutCmd.Flags().String("format", "", fmt.Sprintf("format of the cut file, one of %s, the one of the output file extension or svg if empty",
+		strings.Join(usecases.CutFormats, ", ")))
+	cutCmd.Flags().Float64("tolerance", 0, "maximum distance in mm between the curves and the lines they are flattened into, the configured one if not set")
+	cutCmd.Flags().Bool("flatten", false, "flatten the arcs and curves into lines")
+	addBackingFlag(cutCmd)
+}
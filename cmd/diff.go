@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"theo303/neon-pricer/internal/svg"
+	"theo303/neon-pricer/internal/usecases"
+
+	"github.com/spf13/cobra"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <before.svg> <after.svg>",
+	Short: "Compare two versions of a design.",
+	Long: `Compare two versions of a design.
+Both files are measured and priced, and the groups matched by id to report
+the differences of length, number of strokes, size and price of each group.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, plexi, quantity, err := quoteOptions(cmd)
+		if err != nil {
+			return err
+		}
+		before, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("reading design: %w", err)
+		}
+		after, err := os.ReadFile(args[1])
+		if err != nil {
+			return fmt.Errorf("reading design: %w", err)
+		}
+		delta, err := usecases.DiffDesigns(before, after, config, plexi, quantity, svg.Limits{})
+		if err != nil {
+			return err
+		}
+		return printResult(cmd, deltaResult{delta})
+	},
+}
+
+// deltaResult is written as the comparison of designs of the json api.
+type deltaResult struct {
+	usecases.QuoteDelta
+}
+
+func (r deltaResult) text(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "GROUP\tLENGTH (mm)\tDIFF\tSTROKES\tDIFF\tSIZE (mm)\tPRICE\tDIFF\t")
+	for _, g := range r.Groups {
+		id := g.ID
+		switch {
+		case g.Added:
+			id += " (added)"
+		case g.Removed:
+			id += " (removed)"
+		}
+		fmt.Fprintf(w, "%s\t%.1f\t%+.1f\t%d\t%+d\t%.0f x %.0f\t%.2f\t%+.2f\t\n",
+			id, g.NewLength, g.LengthDifference, g.NewStrokes, g.NewStrokes-g.OldStrokes,
+			g.NewWidth, g.NewHeight, g.NewPrice, g.PriceDifference)
+	}
+	fmt.Fprintf(w, "TOTAL\t\t\t\t\t\t%.2f\t%+.2f (%+.2f%%)\t\n", r.NewTotal, r.Difference, r.DifferencePercent)
+	return w.Flush()
+}
+
+func (r deltaResult) rows() [][]string {
+	rows := [][]string{{"id", "added", "removed",
+		"old_length_mm", "new_length_mm", "length_difference_mm", "old_strokes", "new_strokes",
+		"old_width_mm", "new_width_mm", "old_height_mm", "new_height_mm",
+		"old_price", "new_price", "price_difference"}}
+	for _, g := range r.Groups {
+		rows = append(rows, []string{g.ID, strconv.FormatBool(g.Added), strconv.FormatBool(g.Removed),
+			formatFloat(g.OldLength), formatFloat(g.NewLength), formatFloat(g.LengthDifference),
+			strconv.Itoa(g.OldStrokes), strconv.Itoa(g.NewStrokes),
+			formatFloat(g.OldWidth), formatFloat(g.NewWidth), formatFloat(g.OldHeight), formatFloat(g.NewHeight),
+			formatFloat(g.OldPrice), formatFloat(g.NewPrice), formatFloat(g.PriceDifference)})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	addQuoteFlags(diffCmd)
+}
@@ -1,8 +1,12 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
-	"theo303/neon-pricer/conf"
+	"io"
+	"slices"
+
+	"theo303/neon-pricer/internal/domain"
 	"theo303/neon-pricer/internal/usecases"
 
 	"github.com/spf13/cobra"
@@ -10,42 +14,111 @@ import (
 
 // lengthCmd represents the lengthCmd command
 var lengthCmd = &cobra.Command{
-	Use:   "length",
-	Short: "Calculate the total length of all forms in a svg file.",
-	Long: `Calculate the total length of all forms in a svg file.
+	Use:   "length <file.svg|file.dxf>",
+	Short: "Calculate the total length of all forms in a svg or dxf file.",
+	Long: `Calculate the total length of all forms in a svg or dxf file.
 Each groups of forms will be measured independantly and then summed together.
 	
-Rectangles, circles and paths are supported.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(args)
+Rectangles, circles and paths are supported. The layers of dxf files are
+read as groups, with their lines, polylines, arcs, circles, ellipses and splines.
 
-		conf, err := conf.Load()
+LED strips can only be cut at the cut interval of their LED, the length of strip
+to buy for each stroke and the resulting waste are given for the groups with a silicone.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, err := loadConfig(cmd)
 		if err != nil {
-			panic(err)
+			return err
 		}
 
 		groupID, err := cmd.Flags().GetString("group")
 		if err != nil {
-			panic(err)
+			return err
 		}
 
-		formsGroups, err := usecases.ParseSVGFile(args[0], groupID)
+		formsGroups, err := usecases.ParseDesignFile(args[0], groupID, conf.Scale)
 		if err != nil {
-			panic(err)
+			return err
 		}
 		lengths, err := usecases.GetLengths(formsGroups)
 		if err != nil {
-			panic(err)
+			return err
+		}
+		sizes, err := usecases.GetSizes(formsGroups, conf.Scale)
+		if err != nil {
+			return err
+		}
+		strips, err := usecases.GetLEDStrips(conf.LEDs, sizes)
+		if err != nil {
+			return err
 		}
-		var totalLength float64
+
+		var res lengthResult
 		for id, length := range lengths {
-			fmt.Printf("%s: %.2fpx, %.2fmm\n", id, length, length*1000/conf.Scale)
-			totalLength += length
+			g := groupLength{
+				Group:    id,
+				LengthPx: length,
+				LengthMm: length * 1000 / conf.Scale,
+				Strips:   strips[id],
+			}
+			for _, strip := range g.Strips {
+				g.PurchasedMm += strip.PurchasedMm
+				g.WasteMm += strip.WasteMm
+			}
+			res.Groups = append(res.Groups, g)
+			res.LengthPx += length
+			res.LengthMm += length * 1000 / conf.Scale
+			res.PurchasedMm += g.PurchasedMm
+			res.WasteMm += g.WasteMm
 		}
-		fmt.Printf("total: %.2f\n", totalLength)
+		slices.SortFunc(res.Groups, func(a, b groupLength) int { return cmp.Compare(a.Group, b.Group) })
+		return printResult(cmd, res)
 	},
 }
 
+type groupLength struct {
+	Group    string  `json:"group"`
+	LengthPx float64 `json:"length_px"`
+	LengthMm float64 `json:"length_mm"`
+	// PurchasedMm is the length of LED strip to buy for the group.
+	PurchasedMm float64           `json:"purchased_mm,omitempty"`
+	WasteMm     float64           `json:"waste_mm,omitempty"`
+	Strips      []domain.StripCut `json:"strips,omitempty"`
+}
+
+type lengthResult struct {
+	Groups      []groupLength `json:"groups"`
+	LengthPx    float64       `json:"length_px"`
+	LengthMm    float64       `json:"length_mm"`
+	PurchasedMm float64       `json:"purchased_mm"`
+	WasteMm     float64       `json:"waste_mm"`
+}
+
+func (r lengthResult) text(w io.Writer) error {
+	for _, g := range r.Groups {
+		fmt.Fprintf(w, "%s: %.2fpx, %.2fmm\n", g.Group, g.LengthPx, g.LengthMm)
+		if len(g.Strips) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "  LED to buy: %.2fmm, %.2fmm wasted\n", g.PurchasedMm, g.WasteMm)
+		for i, strip := range g.Strips {
+			fmt.Fprintf(w, "    stroke %d: %.2fmm, buy %.2fmm\n", i+1, strip.LengthMm, strip.PurchasedMm)
+		}
+	}
+	_, err := fmt.Fprintf(w, "total: %.2fpx, %.2fmm, %.2fmm of LED to buy, %.2fmm wasted\n",
+		r.LengthPx, r.LengthMm, r.PurchasedMm, r.WasteMm)
+	return err
+}
+
+func (r lengthResult) rows() [][]string {
+	rows := [][]string{{"group", "length_px", "length_mm", "purchased_mm", "waste_mm"}}
+	for _, g := range r.Groups {
+		rows = append(rows, []string{g.Group, formatFloat(g.LengthPx), formatFloat(g.LengthMm),
+			formatFloat(g.PurchasedMm), formatFloat(g.WasteMm)})
+	}
+	return rows
+}
+
 func init() {
 	rootCmd.AddCommand(lengthCmd)
 
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of the results of the commands.
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputCSV  = "csv"
+	outputYAML = "yaml"
+)
+
+// result is the result of a command, it is written as json and yaml with its json field names.
+type result interface {
+	// text writes the result for humans.
+	text(w io.Writer) error
+	// rows returns the result as a table, the first row holding the column names.
+	rows() [][]string
+}
+
+// checkOutputFormat checks the output flag before the command runs.
+func checkOutputFormat(cmd *cobra.Command) error {
+	format, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	switch format {
+	case outputText, outputJSON, outputCSV, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, expected %s, %s, %s or %s",
+		format, outputText, outputJSON, outputCSV, outputYAML)
+}
+
+// printResult writes the result on the standard output in the format given by the output flag.
+func printResult(cmd *cobra.Command, r result) error {
+	format, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	return writeResult(cmd.OutOrStdout(), format, r)
+}
+
+// writeResult writes the result to out in the format.
+func writeResult(out io.Writer, format string, r result) error {
+	switch format {
+	case outputText:
+		return r.text(out)
+	case outputJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(r)
+	case outputCSV:
+		w := csv.NewWriter(out)
+		if err := w.WriteAll(r.rows()); err != nil {
+			return fmt.Errorf("writing csv: %w", err)
+		}
+		return nil
+	case outputYAML:
+		return writeYAML(out, r)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// writeYAML writes v as yaml, with the field names and order of its json encoding.
+func writeYAML(w io.Writer, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return fmt.Errorf("converting json to yaml: %w", err)
+	}
+	blockStyle(&node)
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return fmt.Errorf("writing yaml: %w", err)
+	}
+	return encoder.Close()
+}
+
+// blockStyle removes the flow style and quotes json documents are decoded with,
+// strings are still quoted when needed to keep their type.
+func blockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// formatFloat formats numbers in csv cells.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
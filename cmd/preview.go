@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"theo303/neon-pricer/internal/svg"
+	"theo303/neon-pricer/internal/usecases"
+
+	"github.com/spf13/cobra"
+)
+
+// previewCmd represents the preview command
+var previewCmd = &cobra.Command{
+	Use:   "preview <file.svg>",
+	Short: "Render a png image of the lit neons of a svg file.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if err := backingOption(cmd, &config); err != nil {
+			return err
+		}
+		plexi, err := cmd.Flags().GetString("plexi")
+		if err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output-file")
+		if err != nil {
+			return err
+		}
+		width, err := cmd.Flags().GetInt("width")
+		if err != nil {
+			return err
+		}
+		dark, err := cmd.Flags().GetBool("dark")
+		if err != nil {
+			return err
+		}
+		if output == "" {
+			output = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".png"
+		}
+
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("reading design: %w", err)
+		}
+		quote, err := usecases.NewQuote(filepath.Base(args[0]), raw, config, plexi, 1, svg.Limits{})
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating preview: %w", err)
+		}
+		defer f.Close()
+		if err := usecases.WritePreview(f, quote, usecases.PreviewOptions{Width: width, Dark: dark}); err != nil {
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("writing preview: %w", err)
+		}
+		return printResult(cmd, fileResult{OutputFile: output})
+	},
+}
+
+// fileResult is the result of the commands writing a file.
+type fileResult struct {
+	OutputFile string `json:"output_file"`
+}
+
+func (r fileResult) text(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "written to %s\n", r.OutputFile)
+	return err
+}
+
+func (r fileResult) rows() [][]string {
+	return [][]string{{"output_file"}, {r.OutputFile}}
+}
+
+func init() {
+	rootCmd.AddCommand(previewCmd)
+
+	previewCmd.Flags().String("plexi", "", "plexi used for the backing, the default plexi if empty")
+	previewCmd.Flags().StringP("output-file", "o", "", "png file to write, the svg file name with a png extension if empty")
+	previewCmd.Flags().Int("width", usecases.DefaultPreviewWidth, "width of the image in pixels")
+	previewCmd.Flags().Bool("dark", false, "draw the design on a dark background instead of the plexi")
+	addBackingFlag(previewCmd)
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+	"theo303/neon-pricer/internal/usecases"
+
+	"github.com/spf13/cobra"
+)
+
+// quoteCmd represents the quote command
+var quoteCmd = &cobra.Command{
+	Use:   "quote <file.svg|file.dxf|dir>",
+	Short: "Price a svg or dxf file and produce the customer quote.",
+	Long: `Price a svg or dxf file and produce the customer quote.
+The design is measured and priced as by the web interface, with the
+configuration given by --config. The prices can be taken from another
+file with --price-list, holding the pricing lists and prices as in the
+configuration file, its other sections being ignored.
+
+The layers of dxf drawings are read as the groups of svg files, their
+lengths being converted from the drawing units to millimetres.
+
+With --watch, the file, or the svg and dxf files of the directory, are quoted again
+each time they are saved, and the differences with the previous version
+printed, until interrupted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, plexi, quantity, err := quoteOptions(cmd)
+		if err != nil {
+			return err
+		}
+		pdfPath, err := cmd.Flags().GetString("pdf")
+		if err != nil {
+			return err
+		}
+		save, err := cmd.Flags().GetBool("save")
+		if err != nil {
+			return err
+		}
+		watch, err := cmd.Flags().GetBool("watch")
+		if err != nil {
+			return err
+		}
+		if watch {
+			return watchQuotes(cmd, args[0], config, plexi, quantity)
+		}
+
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("reading design: %w", err)
+		}
+		quote, err := usecases.NewQuote(filepath.Base(args[0]), raw, config, plexi, quantity, svg.Limits{})
+		if err != nil {
+			return err
+		}
+		if save {
+			quotes, err := requireQuoteStore(cmd)
+			if err != nil {
+				return err
+			}
+			if err := quotes.Save(&quote); err != nil {
+				return fmt.Errorf("saving quote: %w", err)
+			}
+		}
+
+		res, err := newQuoteResult(quote)
+		if err != nil {
+			return err
+		}
+		if err := printResult(cmd, res); err != nil {
+			return err
+		}
+
+		if pdfPath == "" {
+			return nil
+		}
+		f, err := os.Create(pdfPath)
+		if err != nil {
+			return fmt.Errorf("creating pdf: %w", err)
+		}
+		defer f.Close()
+		if err := usecases.WriteQuotePDF(f, quote, config.Branding); err != nil {
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("writing pdf: %w", err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "pdf written to %s\n", pdfPath)
+		return nil
+	},
+}
+
+// quoteResult is written as the quote of the json api, with its detailed lines.
+type quoteResult struct {
+	domain.Quote
+	Lines []domain.QuoteLine `json:"lines"`
+}
+
+func newQuoteResult(quote domain.Quote) (quoteResult, error) {
+	lines, err := usecases.QuoteLines(quote)
+	if err != nil {
+		return quoteResult{}, err
+	}
+	if lines == nil {
+		lines = []domain.QuoteLine{}
+	}
+	return quoteResult{Quote: quote, Lines: lines}, nil
+}
+
+func (r quoteResult) text(out io.Writer) error {
+	if r.ID != "" {
+		fmt.Fprintf(out, "quote %s\n", r.ID)
+	}
+	fmt.Fprintf(out, "file: %s (sha256 %s)\n", r.FileName, r.Hash)
+	fmt.Fprintf(out, "date: %s\n", r.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(out, "plexi: %s\n", r.Plexi)
+	if r.Copies() > 1 {
+		fmt.Fprintf(out, "quantity: %d signs at %.2f %s\n", r.Copies(), r.Prices.Total(), r.Config.Branding.Currency)
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "GROUP\tDESCRIPTION\tQUANTITY\tUNIT\tUNIT PRICE\tAMOUNT\t")
+	for _, l := range r.Lines {
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%s\t%.2f\t%.2f\t\n",
+			l.Group, l.Description, l.Quantity, l.Unit, l.UnitPrice, l.Amount)
+	}
+	fmt.Fprintf(w, "\t\t\t\tTOTAL\t%.2f %s\t\n", r.Total, r.Config.Branding.Currency)
+	return w.Flush()
+}
+
+func (r quoteResult) rows() [][]string {
+	rows := [][]string{{"group", "description", "quantity", "unit", "unit_price", "amount"}}
+	for _, l := range r.Lines {
+		rows = append(rows, []string{l.Group, l.Description, formatFloat(l.Quantity), l.Unit,
+			formatFloat(l.UnitPrice), formatFloat(l.Amount)})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(quoteCmd)
+
+	addQuoteFlags(quoteCmd)
+	quoteCmd.Flags().String("pdf", "", "write the customer quote as pdf to this file")
+	quoteCmd.Flags().Bool("save", false, "save the quote in the quotes database")
+	quoteCmd.Flags().BoolP("watch", "w", false, "quote the design again each time it is saved")
+	quoteCmd.MarkFlagsMutuallyExclusive("watch", "pdf")
+	quoteCmd.MarkFlagsMutuallyExclusive("watch", "save")
+}
+
+// addQuoteFlags adds the flags read by quoteOptions.
+func addQuoteFlags(cmd *cobra.Command) {
+	cmd.Flags().String("plexi", "", "plexi used for the backing, the default plexi if empty")
+	cmd.Flags().String("price-list", "", "file holding the prices to use instead of the ones of --config, as in a configuration file")
+	cmd.Flags().IntP("quantity", "q", 1, "number of identical signs")
+	addBackingFlag(cmd)
+}
+
+// addBackingFlag adds the flag read by backingOption.
+func addBackingFlag(cmd *cobra.Command) {
+	cmd.Flags().String("backing", "", fmt.Sprintf("backing generated for designs without DECOUPE group, one of %s, the configured one if not set",
+		strings.Join(conf.BackingModes, ", ")))
+}
+
+// backingOption sets the backing mode of the configuration to the one of the backing flag, if set.
+func backingOption(cmd *cobra.Command, config *conf.Configuration) error {
+	if !cmd.Flags().Changed("backing") {
+		return nil
+	}
+	mode, err := cmd.Flags().GetString("backing")
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(conf.BackingModes, mode) {
+		return fmt.Errorf("unknown backing mode %q, expected one of %s", mode, strings.Join(conf.BackingModes, ", "))
+	}
+	config.Backing.Mode = mode
+	return nil
+}
+
+// quoteOptions returns the configuration, with the prices of the price list if any,
+// the plexi and the quantity to quote designs with.
+func quoteOptions(cmd *cobra.Command) (conf.Configuration, string, int, error) {
+	config, err := loadConfig(cmd)
+	if err != nil {
+		return conf.Configuration{}, "", 0, err
+	}
+	plexi, err := cmd.Flags().GetString("plexi")
+	if err != nil {
+		return conf.Configuration{}, "", 0, err
+	}
+	priceList, err := cmd.Flags().GetString("price-list")
+	if err != nil {
+		return conf.Configuration{}, "", 0, err
+	}
+	if priceList != "" {
+		config.Pricing, err = conf.LoadPricing(priceList)
+		if err != nil {
+			return conf.Configuration{}, "", 0, fmt.Errorf("loading price list: %w", err)
+		}
+	}
+	quantity, err := cmd.Flags().GetInt("quantity")
+	if err != nil {
+		return conf.Configuration{}, "", 0, err
+	}
+	if err := backingOption(cmd, &config); err != nil {
+		return conf.Configuration{}, "", 0, err
+	}
+	return config, plexi, quantity, nil
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/storage"
+	"theo303/neon-pricer/internal/usecases"
+
+	"github.com/spf13/cobra"
+)
+
+const dateLayout = "2006-01-02"
+
+// quotesCmd represents the quotes command
+var quotesCmd = &cobra.Command{
+	Use:   "quotes",
+	Short: "Consult the quotes saved by the server.",
+}
+
+var quotesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved quotes, newest first.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		quotes, err := requireQuoteStore(cmd)
+		if err != nil {
+			return err
+		}
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		list, err := quotes.List(filter)
+		if err != nil {
+			return err
+		}
+		if list == nil {
+			list = []domain.Quote{}
+		}
+		return printResult(cmd, quoteList(list))
+	},
+}
+
+// quoteList is written as the list of quotes of the json api.
+type quoteList []domain.Quote
+
+func (l quoteList) text(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDATE\tFILE\tPLEXI\tTOTAL")
+	for _, q := range l {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.2f\n",
+			q.ID, q.CreatedAt.Format("2006-01-02 15:04"), q.FileName, q.Plexi, q.Total)
+	}
+	return w.Flush()
+}
+
+func (l quoteList) rows() [][]string {
+	rows := [][]string{{"id", "created_at", "file_name", "hash", "plexi", "total"}}
+	for _, q := range l {
+		rows = append(rows, []string{q.ID, q.CreatedAt.Format(time.RFC3339), q.FileName, q.Hash, q.Plexi, formatFloat(q.Total)})
+	}
+	return rows
+}
+
+var quotesShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show the detail of a saved quote.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		quotes, err := requireQuoteStore(cmd)
+		if err != nil {
+			return err
+		}
+		q, err := quotes.Get(args[0])
+		if err != nil {
+			return err
+		}
+		res, err := newQuoteResult(q)
+		if err != nil {
+			return err
+		}
+		return printResult(cmd, res)
+	},
+}
+
+var quotesSVGCmd = &cobra.Command{
+	Use:   "svg <id>",
+	Short: "Write the svg file of a saved quote.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		quotes, err := requireQuoteStore(cmd)
+		if err != nil {
+			return err
+		}
+		q, err := quotes.Get(args[0])
+		if err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output-file")
+		if err != nil {
+			return err
+		}
+		if output == "" {
+			_, err = cmd.OutOrStdout().Write(q.SVG)
+			return err
+		}
+		return os.WriteFile(output, q.SVG, 0o644)
+	},
+}
+
+var quotesRepriceCmd = &cobra.Command{
+	Use:   "reprice",
+	Short: "Compare the prices of saved quotes with another price list.",
+	Long: `Compare the prices of saved quotes with another price list.
+Quotes are priced again with the configuration file given by --with, or the
+current configuration by default, using the sizes saved with each quote.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		quotes, err := requireQuoteStore(cmd)
+		if err != nil {
+			return err
+		}
+		filter, err := filterFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		with, err := cmd.Flags().GetString("with")
+		if err != nil {
+			return err
+		}
+		var config conf.Configuration
+		if with != "" {
+			config, err = conf.LoadFile(with)
+		} else {
+			config, err = loadConfig(cmd)
+		}
+		if err != nil {
+			return err
+		}
+
+		list, err := quotes.List(filter)
+		if err != nil {
+			return err
+		}
+		// the quotes without sizes are measured again from their svg file, which is not listed.
+		for i, q := range list {
+			if len(q.Sizes) == 0 {
+				if list[i], err = quotes.Get(q.ID); err != nil {
+					return err
+				}
+			}
+		}
+		report, err := usecases.RepriceQuotes(list, config)
+		if err != nil {
+			return err
+		}
+
+		return printResult(cmd, repriceResult{report})
+	},
+}
+
+// repriceResult is written as the repricing report of the json api.
+type repriceResult struct {
+	usecases.RepricingReport
+}
+
+func (r repriceResult) text(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "ID\tFILE\tOLD\tNEW\tDIFF\tDIFF %\t")
+	for _, q := range r.Quotes {
+		fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%+.2f\t%+.2f%%\t\n",
+			q.QuoteID, q.FileName, q.OldTotal, q.NewTotal, q.Difference, q.DifferencePercent)
+	}
+	fmt.Fprintf(w, "total\t\t%.2f\t%.2f\t%+.2f\t%+.2f%%\t\n",
+		r.OldTotal, r.NewTotal, r.Difference, r.DifferencePercent)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, q := range r.Skipped {
+		if _, err := fmt.Fprintf(out, "skipped %s (%s): %s\n", q.QuoteID, q.FileName, q.Reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r repriceResult) rows() [][]string {
+	rows := [][]string{{"quote_id", "file_name", "old_total", "new_total", "difference", "difference_percent"}}
+	for _, q := range r.Quotes {
+		rows = append(rows, []string{q.QuoteID, q.FileName, formatFloat(q.OldTotal), formatFloat(q.NewTotal),
+			formatFloat(q.Difference), formatFloat(q.DifferencePercent)})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(quotesCmd)
+	quotesCmd.AddCommand(quotesListCmd, quotesShowCmd, quotesSVGCmd, quotesRepriceCmd)
+
+	addFilterFlags(quotesListCmd)
+	addFilterFlags(quotesRepriceCmd)
+	quotesRepriceCmd.Flags().String("with", "", "configuration file holding the new price list")
+
+	quotesSVGCmd.Flags().StringP("output-file", "o", "", "svg file to write, standard output by default")
+}
+
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("search", "s", "", "search in file names and ids")
+	cmd.Flags().String("hash", "", "prefix of the sha256 of the svg file")
+	cmd.Flags().String("since", "", "only quotes computed since this date (2006-01-02)")
+	cmd.Flags().String("until", "", "only quotes computed until this date included (2006-01-02)")
+	cmd.Flags().IntP("limit", "n", 0, "maximum number of quotes")
+}
+
+func requireQuoteStore(cmd *cobra.Command) (*storage.QuoteStore, error) {
+	quotes, err := quoteStore(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if quotes == nil {
+		return nil, errors.New("no quote database, set --db")
+	}
+	return quotes, nil
+}
+
+func filterFromFlags(cmd *cobra.Command) (storage.Filter, error) {
+	var filter storage.Filter
+	var err error
+	if filter.Search, err = cmd.Flags().GetString("search"); err != nil {
+		return storage.Filter{}, err
+	}
+	if filter.Hash, err = cmd.Flags().GetString("hash"); err != nil {
+		return storage.Filter{}, err
+	}
+	if filter.Limit, err = cmd.Flags().GetInt("limit"); err != nil {
+		return storage.Filter{}, err
+	}
+	since, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return storage.Filter{}, err
+	}
+	if since != "" {
+		if filter.Since, err = time.ParseInLocation(dateLayout, since, time.Local); err != nil {
+			return storage.Filter{}, fmt.Errorf("parsing since: %w", err)
+		}
+	}
+	until, err := cmd.Flags().GetString("until")
+	if err != nil {
+		return storage.Filter{}, err
+	}
+	if until != "" {
+		if filter.Until, err = time.ParseInLocation(dateLayout, until, time.Local); err != nil {
+			return storage.Filter{}, fmt.Errorf("parsing until: %w", err)
+		}
+		filter.Until = filter.Until.AddDate(0, 0, 1)
+	}
+	return filter, nil
+}
@@ -1,15 +1,33 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strings"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/storage"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+// envPrefix prefixes the environment variables that can be used instead of flags.
+const envPrefix = "NEON_PRICER_"
+
 var rootCmd = &cobra.Command{
 	Use:   "neon-pricer",
 	Short: "Calculate the price of a neon from a SVG file.",
-	Long:  `Calculate the price of a neon from a SVG file.`,
+	Long: `Calculate the price of a neon from a SVG file.
+
+Every flag can also be set with an environment variable named after it,
+such as ` + envPrefix + `CONFIG for --config or ` + envPrefix + `TLS_CERT for --tls-cert.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setFlagsFromEnv(cmd); err != nil {
+			return err
+		}
+		return checkOutputFormat(cmd)
+	},
 }
 
 func Execute() {
@@ -20,4 +38,42 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().String("config", "", "configuration file, config.yaml in the working directory by default")
+	rootCmd.PersistentFlags().String("db", "quotes.db", "quote database file")
+	rootCmd.PersistentFlags().String("output", outputText, "output format of the results: text, json, csv or yaml")
+}
+
+// setFlagsFromEnv sets the flags that were not given on the command line
+// from their environment variable, if any.
+func setFlagsFromEnv(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		envName := envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		value, ok := os.LookupEnv(envName)
+		if err != nil || f.Changed || !ok {
+			return
+		}
+		if setErr := cmd.Flags().Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("setting flag %s from %s: %w", f.Name, envName, setErr)
+		}
+	})
+	return err
+}
+
+// quoteStore returns the quote store of the database given by the db flag, or nil if it is empty.
+func quoteStore(cmd *cobra.Command) (*storage.QuoteStore, error) {
+	path, err := cmd.Flags().GetString("db")
+	if err != nil || path == "" {
+		return nil, err
+	}
+	return storage.NewQuoteStore(path), nil
+}
+
+// loadConfig loads the configuration file given by the config flag.
+func loadConfig(cmd *cobra.Command) (conf.Configuration, error) {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return conf.Configuration{}, err
+	}
+	return conf.Load(path)
 }
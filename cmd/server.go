@@ -1,8 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
 	"theo303/neon-pricer/conf"
 	"theo303/neon-pricer/internal/http"
+	"theo303/neon-pricer/templates"
 
 	"github.com/spf13/cobra"
 )
@@ -11,15 +20,40 @@ import (
 var serverCmd = &cobra.Command{
 	Use:   "server",
 	Short: "Starts the server.",
+	Long: `Starts the server.
+The configuration file is watched and reloaded each time it changes,
+an invalid file is ignored and the previous configuration kept.
+
+Computed quotes are saved in the quote database, unless --db is empty.
+
+The server stops gracefully on SIGINT or SIGTERM, waiting for pending
+requests up to the shutdown timeout.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		conf, err := conf.Load()
+		config, err := loadConfig(cmd)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("configuration loaded: %+v\n", config)
+
+		serverConfig, err := serverConfigFromFlags(cmd)
+		if err != nil {
+			panic(err)
+		}
+
+		quotes, err := quoteStore(cmd)
 		if err != nil {
 			panic(err)
 		}
 
-		api := http.NewAPI(conf, 8080)
+		store := conf.NewStore(config)
+		conf.Watch(store)
+
+		api := http.NewAPI(store, quotes, serverConfig)
 
-		if err = api.Run(); err != nil {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if err = api.Run(ctx); err != nil {
 			panic(err)
 		}
 	},
@@ -27,4 +61,72 @@ var serverCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(serverCmd)
+
+	serverCmd.Flags().String("addr", ":8080", "listen address")
+	serverCmd.Flags().String("templates", "", "templates directory, embedded templates are used by default")
+	serverCmd.Flags().String("tls-cert", "", "TLS certificate file")
+	serverCmd.Flags().String("tls-key", "", "TLS key file")
+	serverCmd.Flags().Duration("read-timeout", 30*time.Second, "maximum duration for reading a request, including its body")
+	serverCmd.Flags().Duration("write-timeout", 60*time.Second, "maximum duration before timing out writes of a response")
+	serverCmd.Flags().Duration("idle-timeout", 120*time.Second, "maximum duration to wait for the next request on a keep-alive connection")
+	serverCmd.Flags().Duration("shutdown-timeout", 15*time.Second, "maximum duration to wait for pending requests on shutdown")
+	serverCmd.Flags().Int64("max-upload-size", 10<<20, "maximum size in bytes of an uploaded design, 0 for no limit")
+	serverCmd.Flags().Duration("compute-timeout", 20*time.Second, "time budget to price an uploaded design, 0 for no limit")
+	serverCmd.Flags().Int("max-computations", runtime.NumCPU(), "maximum number of designs priced at once, 0 for no limit")
+	serverCmd.Flags().Int("max-elements", 100_000, "maximum number of svg elements in a design, 0 for no limit")
+	serverCmd.Flags().Int("max-depth", 64, "maximum nesting depth of svg elements in a design, 0 for no limit")
+	serverCmd.Flags().Int("max-path-commands", 500_000, "maximum number of path commands in a design, 0 for no limit")
+}
+
+func serverConfigFromFlags(cmd *cobra.Command) (http.ServerConfig, error) {
+	var serverConfig http.ServerConfig
+	var err error
+	if serverConfig.Addr, err = cmd.Flags().GetString("addr"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.TLSCertFile, err = cmd.Flags().GetString("tls-cert"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.TLSKeyFile, err = cmd.Flags().GetString("tls-key"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.ReadTimeout, err = cmd.Flags().GetDuration("read-timeout"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.WriteTimeout, err = cmd.Flags().GetDuration("write-timeout"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.IdleTimeout, err = cmd.Flags().GetDuration("idle-timeout"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.ShutdownTimeout, err = cmd.Flags().GetDuration("shutdown-timeout"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.MaxUploadBytes, err = cmd.Flags().GetInt64("max-upload-size"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.ComputeTimeout, err = cmd.Flags().GetDuration("compute-timeout"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.MaxComputations, err = cmd.Flags().GetInt("max-computations"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.SVGLimits.MaxElements, err = cmd.Flags().GetInt("max-elements"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.SVGLimits.MaxDepth, err = cmd.Flags().GetInt("max-depth"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	if serverConfig.SVGLimits.MaxPathCommands, err = cmd.Flags().GetInt("max-path-commands"); err != nil {
+		return http.ServerConfig{}, err
+	}
+	templatesDir, err := cmd.Flags().GetString("templates")
+	if err != nil {
+		return http.ServerConfig{}, err
+	}
+	serverConfig.Templates = templates.FS
+	if templatesDir != "" {
+		serverConfig.Templates = os.DirFS(templatesDir)
+	}
+	return serverConfig, serverConfig.Validate()
 }
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"cmp"
 	"fmt"
-	"theo303/neon-pricer/conf"
+	"io"
+	"slices"
+	"strconv"
+
+	"theo303/neon-pricer/internal/domain"
 	"theo303/neon-pricer/internal/usecases"
 
 	"github.com/spf13/cobra"
@@ -10,39 +15,108 @@ import (
 
 // sizeCmd represents the sizeCmd command
 var sizeCmd = &cobra.Command{
-	Use:   "size",
-	Short: "Calculate the global superficy of  a svg file.",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(args)
-
-		conf, err := conf.Load()
+	Use:   "size <file.svg|file.dxf>",
+	Short: "Calculate the global superficy of a svg or dxf file.",
+	Long: `Calculate the global superficy of a svg or dxf file.
+The radius of the tightest curve of each group is given, with the curves bent
+tighter than the minimum bend radius of the silicone of the group, located in
+the coordinates of the design, and the silicone to use instead if any.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, err := loadConfig(cmd)
 		if err != nil {
-			panic(err)
+			return err
 		}
 
 		groupID, err := cmd.Flags().GetString("group")
 		if err != nil {
-			panic(err)
+			return err
 		}
 
-		formsGroups, err := usecases.ParseSVGFile(args[0], groupID)
+		formsGroups, err := usecases.ParseDesignFile(args[0], groupID, conf.Scale)
 		if err != nil {
-			panic(err)
+			return err
 		}
 		bounds, err := usecases.GetBounds(formsGroups)
 		if err != nil {
-			panic(err)
+			return err
+		}
+
+		polylines, err := usecases.GetPolylines(formsGroups)
+		if err != nil {
+			return err
 		}
+		violations, err := usecases.GetBendViolations(polylines, conf.Silicones, conf.Scale, conf.DesignRules.CornerAngleDeg)
+		if err != nil {
+			return err
+		}
+		radiuses := usecases.GetTightestRadiuses(polylines, conf.Silicones, conf.Scale, conf.DesignRules.CornerAngleDeg)
+
+		var res sizeResult
 		for id, b := range bounds {
-			width := b.Width()
-			height := b.Height()
-			fmt.Printf("%s: width=%.2fpx/%.2fmm height=%.2fpx/%.2fmm\n",
-				id, width, width*1000/conf.Scale, height, height*1000/conf.Scale)
-			// fmt.Printf("%s: %.2fpxˆ2, %.2fmmˆ2\n", id, size, size*1000/conf.Scale)
+			res.Groups = append(res.Groups, groupSize{
+				Group:            id,
+				WidthPx:          b.Width(),
+				WidthMm:          b.Width() * 1000 / conf.Scale,
+				HeightPx:         b.Height(),
+				HeightMm:         b.Height() * 1000 / conf.Scale,
+				TightestRadiusMm: radiuses[id],
+				BendViolations:   violations[id],
+			})
 		}
+		slices.SortFunc(res.Groups, func(a, b groupSize) int { return cmp.Compare(a.Group, b.Group) })
+		return printResult(cmd, res)
 	},
 }
 
+type groupSize struct {
+	Group    string  `json:"group"`
+	WidthPx  float64 `json:"width_px"`
+	WidthMm  float64 `json:"width_mm"`
+	HeightPx float64 `json:"height_px"`
+	HeightMm float64 `json:"height_mm"`
+	// TightestRadiusMm is the radius of the tightest curve, 0 without curves.
+	TightestRadiusMm float64                `json:"tightest_radius_mm,omitempty"`
+	BendViolations   []domain.BendViolation `json:"bend_violations,omitempty"`
+}
+
+type sizeResult struct {
+	Groups []groupSize `json:"groups"`
+}
+
+func (r sizeResult) text(w io.Writer) error {
+	for _, g := range r.Groups {
+		_, err := fmt.Fprintf(w, "%s: width=%.2fpx/%.2fmm height=%.2fpx/%.2fmm",
+			g.Group, g.WidthPx, g.WidthMm, g.HeightPx, g.HeightMm)
+		if err != nil {
+			return err
+		}
+		if g.TightestRadiusMm > 0 {
+			fmt.Fprintf(w, " tightest curve=%.1fmm", g.TightestRadiusMm)
+		}
+		fmt.Fprintln(w)
+		for _, v := range g.BendViolations {
+			fmt.Fprintf(w, "  curve of radius %.1fmm at %.1f,%.1f under the %vmm minimum bend radius",
+				v.RadiusMm, v.X, v.Y, v.MinRadiusMm)
+			if v.RecommendedSizeMm > 0 {
+				fmt.Fprintf(w, ", use the %dmm silicone", v.RecommendedSizeMm)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	return nil
+}
+
+func (r sizeResult) rows() [][]string {
+	rows := [][]string{{"group", "width_px", "width_mm", "height_px", "height_mm", "tightest_radius_mm", "bend_violations"}}
+	for _, g := range r.Groups {
+		rows = append(rows, []string{g.Group,
+			formatFloat(g.WidthPx), formatFloat(g.WidthMm), formatFloat(g.HeightPx), formatFloat(g.HeightMm),
+			formatFloat(g.TightestRadiusMm), strconv.Itoa(len(g.BendViolations))})
+	}
+	return rows
+}
+
 func init() {
 	rootCmd.AddCommand(sizeCmd)
 
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+	"theo303/neon-pricer/internal/usecases"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// watchDelay is waited after the last change of a file before quoting it,
+// editors writing a file in several steps.
+const watchDelay = 300 * time.Millisecond
+
+// watchQuotes quotes the design file, or the svg and dxf files of the directory, each time it is saved
+// and prints the differences with the previous version, until SIGINT or SIGTERM.
+func watchQuotes(cmd *cobra.Command, target string, config conf.Configuration, plexi string, quantity int) error {
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+	dir, watched := target, usecases.IsDesignFile
+	if !info.IsDir() {
+		dir = filepath.Dir(target)
+		watched = func(path string) bool {
+			return path == filepath.Clean(target)
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer watcher.Close()
+	// The directory is watched rather than the file, editors often replace the file when saving.
+	if err := watcher.Add(dir); err != nil {
+		return fmt.Errorf("watching %s: %w", dir, err)
+	}
+
+	w := quoteWatcher{cmd: cmd, config: config, plexi: plexi, quantity: quantity, previous: make(map[string]domain.Quote)}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() && watched(path) {
+			if err := w.quote(path); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "watching %s, press Ctrl+C to stop\n", target)
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	changed := make(chan string)
+	timers := make(map[string]*time.Timer)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			path := filepath.Clean(event.Name)
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) || !watched(path) {
+				continue
+			}
+			if timer, ok := timers[path]; ok {
+				timer.Reset(watchDelay)
+				continue
+			}
+			timers[path] = time.AfterFunc(watchDelay, func() {
+				select {
+				case changed <- path:
+				case <-ctx.Done():
+				}
+			})
+		case path := <-changed:
+			delete(timers, path)
+			if err := w.quote(path); err != nil {
+				return err
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "watch error: %v\n", err)
+		}
+	}
+}
+
+// quoteWatcher quotes the watched files and keeps their last quote.
+type quoteWatcher struct {
+	cmd      *cobra.Command
+	config   conf.Configuration
+	plexi    string
+	quantity int
+	previous map[string]domain.Quote
+}
+
+// quote quotes the file and prints the result with the differences with its previous quote.
+// A file that cannot be quoted is reported and its previous quote kept, it may be saved again.
+func (w *quoteWatcher) quote(path string) error {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading design: %w", err)
+	}
+	previous, hasPrevious := w.previous[path]
+	hash := sha256.Sum256(raw)
+	if hasPrevious && previous.Hash == hex.EncodeToString(hash[:]) {
+		return nil
+	}
+
+	quote, err := usecases.NewQuote(filepath.Base(path), raw, w.config, w.plexi, w.quantity, svg.Limits{})
+	if err != nil {
+		fmt.Fprintf(w.cmd.ErrOrStderr(), "%s %s: %v\n", time.Now().Format(time.TimeOnly), path, err)
+		return nil
+	}
+	w.previous[path] = quote
+
+	res, err := newQuoteResult(quote)
+	if err != nil {
+		return err
+	}
+	watchRes := watchResult{quoteResult: res, File: path}
+	if hasPrevious {
+		delta := usecases.CompareQuotes(previous, quote)
+		watchRes.Delta = &delta
+	}
+	return printResult(w.cmd, watchRes)
+}
+
+// watchResult is written as the quote of the json api, with the differences with the previous version.
+type watchResult struct {
+	quoteResult
+	File  string               `json:"file"`
+	Delta *usecases.QuoteDelta `json:"delta,omitempty"`
+}
+
+func (r watchResult) text(out io.Writer) error {
+	fmt.Fprintf(out, "\n=== %s %s\n", r.CreatedAt.Format(time.TimeOnly), r.File)
+	if err := r.quoteResult.text(out); err != nil {
+		return err
+	}
+	if r.Delta == nil {
+		return nil
+	}
+
+	fmt.Fprintln(out, "\nchanges since the previous version:")
+	return deltaResult{*r.Delta}.text(out)
+}
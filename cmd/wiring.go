@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/usecases"
+
+	"github.com/spf13/cobra"
+)
+
+// wiringCmd represents the wiring command
+var wiringCmd = &cobra.Command{
+	Use:   "wiring <file.svg|file.dxf>",
+	Short: "Draw the wiring plan of a svg or dxf file and estimate the length of its cables.",
+	Long: `Draw the wiring plan of a svg or dxf file and estimate the length of its cables.
+
+The strokes of each group touching each other are joined in runs fed by one LED strip.
+The runs are chained by jumpers, and a cable links the feed point of each run to the
+controller at the power entry of the backing. Cables are routed horizontally then
+vertically behind the backing, their length being the sum of both distances.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		if err := backingOption(cmd, &config); err != nil {
+			return err
+		}
+		output, err := cmd.Flags().GetString("output-file")
+		if err != nil {
+			return err
+		}
+		if output == "" {
+			output = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".wiring.svg"
+		}
+		if cmd.Flags().Changed("entry") {
+			if config.Wiring.PowerEntry, err = cmd.Flags().GetString("entry"); err != nil {
+				return err
+			}
+		}
+
+		raw, err := os.ReadFile(args[0])
+		if err != nil {
+			return fmt.Errorf("reading design: %w", err)
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating wiring plan: %w", err)
+		}
+		defer f.Close()
+		plan, err := usecases.WriteWiring(f, args[0], raw, config)
+		if err != nil {
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("writing wiring plan: %w", err)
+		}
+
+		res := wiringResult{fileResult: fileResult{OutputFile: output}, Groups: []groupWiring{}}
+		toMm := 1000 / config.Scale
+		for _, g := range plan.Groups {
+			cable := g.CableLength * toMm
+			res.Groups = append(res.Groups, groupWiring{
+				Group:          g.ID,
+				FeedPoints:     len(g.Plan.Runs),
+				CableLengthMm:  cable,
+				JumperLengthMm: g.Plan.JumperLength * toMm,
+				CablePrice:     domain.Round(config.CablePricePerMeter * cable / 1000),
+			})
+		}
+		return printResult(cmd, res)
+	},
+}
+
+type groupWiring struct {
+	Group          string  `json:"group"`
+	FeedPoints     int     `json:"feed_points"`
+	CableLengthMm  float64 `json:"cable_length_mm"`
+	JumperLengthMm float64 `json:"jumper_length_mm"`
+	CablePrice     float64 `json:"cable_price"`
+}
+
+type wiringResult struct {
+	fileResult
+	Groups []groupWiring `json:"groups"`
+}
+
+func (r wiringResult) text(w io.Writer) error {
+	for _, g := range r.Groups {
+		fmt.Fprintf(w, "%s: %d feed points, %.0fmm of cable (%.2f), %.0fmm of jumpers\n",
+			g.Group, g.FeedPoints, g.CableLengthMm, g.CablePrice, g.JumperLengthMm)
+	}
+	return r.fileResult.text(w)
+}
+
+func (r wiringResult) rows() [][]string {
+	rows := [][]string{{"group", "feed_points", "cable_length_mm", "jumper_length_mm", "cable_price"}}
+	for _, g := range r.Groups {
+		rows = append(rows, []string{g.Group, fmt.Sprint(g.FeedPoints), formatFloat(g.CableLengthMm),
+			formatFloat(g.JumperLengthMm), formatFloat(g.CablePrice)})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(wiringCmd)
+
+	wiringCmd.Flags().StringP("output-file", "o", "", "svg file to write, the svg file name ending with .wiring.svg if empty")
+	wiringCmd.Flags().String("entry", "",
+		fmt.Sprintf("power entry on the backing, one of %s, the configured one if not set", strings.Join(conf.PowerEntries, ", ")))
+	addBackingFlag(wiringCmd)
+}
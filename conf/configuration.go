@@ -3,54 +3,181 @@ package conf
 import (
 	"fmt"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 )
 
 const configPath = "./"
 
 type Silicone struct {
-	SizeMm        int     `mapstructure:"size"`
-	PricePerMeter float64 `mapstructure:"price"`
+	SizeMm        int     `mapstructure:"size" json:"size"`
+	PricePerMeter float64 `mapstructure:"price" json:"price"`
+	// MinBendRadiusMm is the radius under which the silicone cannot be bent, 0 if unknown.
+	MinBendRadiusMm float64 `mapstructure:"min_bend_radius" json:"min_bend_radius,omitempty"`
 }
 
 type LED struct {
-	Name          string  `mapstructure:"name"`
-	PricePerMeter float64 `mapstructure:"price"`
+	Name          string  `mapstructure:"name" json:"name"`
+	PricePerMeter float64 `mapstructure:"price" json:"price"`
+	// Color is the hex color of the lit LED used in previews, as #rrggbb.
+	Color string `mapstructure:"color" json:"color,omitempty"`
+	// MinLengthMm is the length of the shortest strip that can be cut, 0 if unknown.
+	MinLengthMm float64 `mapstructure:"min_length" json:"min_length,omitempty"`
+	// CutIntervalMm is the interval between the points where the strip can be cut,
+	// 0 if it can be cut anywhere.
+	CutIntervalMm float64 `mapstructure:"cut_interval" json:"cut_interval,omitempty"`
 }
 
 type Plexi struct {
-	Name                string  `mapstructure:"name"`
-	PricePerMeterSquare float64 `mapstructure:"price"`
+	Name                string  `mapstructure:"name" json:"name"`
+	PricePerMeterSquare float64 `mapstructure:"price" json:"price"`
+	// Color is the hex color of the plexi used in previews, as #rrggbb.
+	Color string `mapstructure:"color" json:"color,omitempty"`
+	// SheetWidthMm and SheetHeightMm are the dimensions of the largest sheet available, 0 if unknown.
+	SheetWidthMm  float64 `mapstructure:"sheet_width" json:"sheet_width,omitempty"`
+	SheetHeightMm float64 `mapstructure:"sheet_height" json:"sheet_height,omitempty"`
 }
 
 type Controler struct {
-	Name  string  `mapstructure:"name"`
-	Price float64 `mapstructure:"price"`
+	Name  string  `mapstructure:"name" json:"name"`
+	Price float64 `mapstructure:"price" json:"price"`
 }
 
 type PowerSupply struct {
-	Amp   string  `mapstructure:"amp"`
-	Price float64 `mapstructure:"price"`
+	Amp   string  `mapstructure:"amp" json:"amp"`
+	Price float64 `mapstructure:"price" json:"price"`
 }
 
 type Pricing struct {
-	Silicones     []Silicone    `mapstructure:"silicones"`
-	LEDs          []LED         `mapstructure:"leds"`
-	Plexis        []Plexi       `mapstructure:"plexis"`
-	Controlers    []Controler   `mapstructure:"controlers"`
-	PowerSupplies []PowerSupply `mapstructure:"power_supplies"`
+	Silicones     []Silicone    `mapstructure:"silicones" json:"silicones"`
+	LEDs          []LED         `mapstructure:"leds" json:"leds"`
+	Plexis        []Plexi       `mapstructure:"plexis" json:"plexis"`
+	Controlers    []Controler   `mapstructure:"controlers" json:"controlers"`
+	PowerSupplies []PowerSupply `mapstructure:"power_supplies" json:"power_supplies"`
+	// CornerPrice is the labour price of a sharp corner, where the silicone is cut and joined.
+	CornerPrice float64 `mapstructure:"corner_price" json:"corner_price"`
+	// FeedPointPrice is the labour price of a feed point, where a LED strip is wired.
+	FeedPointPrice float64 `mapstructure:"feed_point_price" json:"feed_point_price"`
+	// JumperPricePerMeter is the price of the cable linking runs of strokes behind the backing.
+	JumperPricePerMeter float64 `mapstructure:"jumper_price" json:"jumper_price"`
+	// CablePricePerMeter is the price of the cable from the feed points to the controller.
+	CablePricePerMeter float64 `mapstructure:"cable_price" json:"cable_price"`
+	// StandoffPrice is the price of a standoff mounting the backing.
+	StandoffPrice float64 `mapstructure:"standoff_price" json:"standoff_price"`
+	// HolePrice is the price of drilling a hole in the backing.
+	HolePrice float64 `mapstructure:"hole_price" json:"hole_price"`
+}
+
+// Branding holds the company details printed on customer documents.
+type Branding struct {
+	Company  string `mapstructure:"company" json:"company"`
+	Address  string `mapstructure:"address" json:"address"`
+	Contact  string `mapstructure:"contact" json:"contact"`
+	Currency string `mapstructure:"currency" json:"currency"`
+	// ValidityDays is the number of days a quote is valid after its creation.
+	ValidityDays int    `mapstructure:"validity_days" json:"validity_days"`
+	Terms        string `mapstructure:"terms" json:"terms"`
+}
+
+// DesignRules holds the constraints checked on designs before building them.
+type DesignRules struct {
+	// MinSpacingMm is the minimum gap between two strokes, 0 to not check it.
+	MinSpacingMm float64 `mapstructure:"min_spacing" json:"min_spacing"`
+	// CornerAngleDeg is the turning angle in degrees above which a vertex is a sharp corner,
+	// 0 to not count corners. The same angle separates the corners from the curves checked
+	// against the minimum bend radius of the silicones, 20 degrees being used there if 0.
+	CornerAngleDeg float64 `mapstructure:"corner_angle" json:"corner_angle"`
+	// JoinToleranceMm is the distance under which the ends of two strokes are joined
+	// and fed by the same LED strip.
+	JoinToleranceMm float64 `mapstructure:"join_tolerance" json:"join_tolerance"`
+}
+
+// Power entries of the backing, where the power cable enters and the controller is.
+const (
+	PowerEntryBottom      = "bottom"
+	PowerEntryTop         = "top"
+	PowerEntryLeft        = "left"
+	PowerEntryRight       = "right"
+	PowerEntryBottomLeft  = "bottom-left"
+	PowerEntryBottomRight = "bottom-right"
+	PowerEntryTopLeft     = "top-left"
+	PowerEntryTopRight    = "top-right"
+	PowerEntryCenter      = "center"
+)
+
+// PowerEntries lists the valid power entries.
+var PowerEntries = []string{
+	PowerEntryBottom, PowerEntryTop, PowerEntryLeft, PowerEntryRight,
+	PowerEntryBottomLeft, PowerEntryBottomRight, PowerEntryTopLeft, PowerEntryTopRight, PowerEntryCenter,
+}
+
+// Wiring holds how the strokes of the signs are wired to their controller.
+type Wiring struct {
+	// PowerEntry is the position on the backing of the power entry, one of PowerEntries,
+	// the middle of its bottom edge if empty.
+	PowerEntry string `mapstructure:"power_entry" json:"power_entry"`
+}
+
+// Mounting holds how the backings are drilled to be mounted on standoffs and let the cables through.
+type Mounting struct {
+	// MarginMm is the minimum distance from the standoff holes to the edges of the backing.
+	MarginMm float64 `mapstructure:"margin" json:"margin"`
+	// StandoffHoleMm and CableHoleMm are the diameters of the holes.
+	StandoffHoleMm float64 `mapstructure:"standoff_hole" json:"standoff_hole"`
+	CableHoleMm    float64 `mapstructure:"cable_hole" json:"cable_hole"`
+}
+
+// Backing modes, how the backing of designs without DECOUPE group is generated.
+const (
+	BackingNone      = "none"
+	BackingRectangle = "rectangle"
+	BackingRounded   = "rounded"
+	BackingContour   = "contour"
+)
+
+// BackingModes lists the valid backing modes.
+var BackingModes = []string{BackingNone, BackingRectangle, BackingRounded, BackingContour}
+
+// Backing holds how the backing is generated around the strokes of designs without DECOUPE group.
+type Backing struct {
+	// Mode is one of BackingModes, the backing is not generated if empty.
+	Mode string `mapstructure:"mode" json:"mode"`
+	// MarginMm is the distance from the edges of the silicones to the edges of the backing.
+	MarginMm float64 `mapstructure:"margin" json:"margin"`
+	// CornerRadiusMm is the radius of the corners of rounded backings.
+	CornerRadiusMm float64 `mapstructure:"corner_radius" json:"corner_radius"`
+}
+
+// CutFile holds how the cut files of the backings are written for the laser cutter.
+type CutFile struct {
+	// ToleranceMm is the maximum distance between the curves and the lines they are flattened into.
+	ToleranceMm float64 `mapstructure:"tolerance" json:"tolerance"`
+	// KeepCurves writes the arcs and curves as such instead of flattening them,
+	// the ones a format cannot hold being flattened anyway.
+	KeepCurves bool `mapstructure:"keep_curves" json:"keep_curves"`
 }
 
 type Configuration struct {
-	Pricing `mapstructure:",squash"`
-	Scale   float64 `mapstructure:"scale"`
+	Pricing     `mapstructure:",squash"`
+	Scale       float64     `mapstructure:"scale" json:"scale"`
+	Branding    Branding    `mapstructure:"branding" json:"branding"`
+	DesignRules DesignRules `mapstructure:"design_rules" json:"design_rules"`
+	Wiring      Wiring      `mapstructure:"wiring" json:"wiring"`
+	Mounting    Mounting    `mapstructure:"mounting" json:"mounting"`
+	Backing     Backing     `mapstructure:"backing" json:"backing"`
+	CutFile     CutFile     `mapstructure:"cut_file" json:"cut_file"`
 }
 
 // Load reads configuration from file.
-func Load() (Configuration, error) {
-	viper.AddConfigPath(configPath)
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
+// If path is empty, config.yaml is searched in the working directory.
+func Load(path string) (Configuration, error) {
+	if path == "" {
+		viper.AddConfigPath(configPath)
+		viper.SetConfigName("config")
+		viper.SetConfigType("yaml")
+	} else {
+		viper.SetConfigFile(path)
+	}
 
 	viper.AutomaticEnv()
 
@@ -59,12 +186,95 @@ func Load() (Configuration, error) {
 		return Configuration{}, err
 	}
 
-	config := Configuration{}
-	err = viper.Unmarshal(&config)
+	config, err := unmarshal(viper.GetViper())
 	if err != nil {
 		return Configuration{}, err
 	}
 
-	fmt.Printf("configuration loaded: %+v\n", config)
+	return config, nil
+}
+
+// Watch watches the configuration file read by Load and swaps the configuration
+// held by the store each time the file changes.
+// If the new file is invalid, the previous configuration is kept.
+func Watch(store *Store) {
+	viper.OnConfigChange(func(fsnotify.Event) {
+		reload(store, viper.ConfigFileUsed())
+	})
+	viper.WatchConfig()
+}
+
+// LoadFile reads the configuration from the file at path,
+// independently of the configuration read by Load.
+func LoadFile(path string) (Configuration, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.AutomaticEnv()
+	if err := v.ReadInConfig(); err != nil {
+		return Configuration{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return unmarshal(v)
+}
+
+// LoadPricing reads the prices and pricing lists from the file at path, the other sections
+// of the file being ignored: a price list needs neither a scale nor design rules.
+func LoadPricing(path string) (Pricing, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.AutomaticEnv()
+	if err := v.ReadInConfig(); err != nil {
+		return Pricing{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var pricing Pricing
+	if err := v.Unmarshal(&pricing); err != nil {
+		return Pricing{}, err
+	}
+	if err := pricing.Validate(); err != nil {
+		return Pricing{}, fmt.Errorf("invalid price list: %w", err)
+	}
+	return pricing, nil
+}
+
+func reload(store *Store, path string) {
+	config, err := LoadFile(path)
+	if err != nil {
+		fmt.Printf("reloading configuration: keeping previous configuration, %s\n", err)
+		return
+	}
+
+	previous := store.Swap(config)
+	changes := Diff(previous, config)
+	if len(changes) == 0 {
+		fmt.Printf("configuration reloaded from %s: no change\n", path)
+		return
+	}
+	fmt.Printf("configuration reloaded from %s:\n", path)
+	for _, change := range changes {
+		fmt.Printf("\t%s\n", change)
+	}
+}
+
+func unmarshal(v *viper.Viper) (Configuration, error) {
+	v.SetDefault("branding.currency", "EUR")
+	v.SetDefault("branding.validity_days", 30)
+	v.SetDefault("design_rules.corner_angle", 45)
+	v.SetDefault("wiring.power_entry", PowerEntryBottom)
+	v.SetDefault("mounting.margin", 20)
+	v.SetDefault("mounting.standoff_hole", 8)
+	v.SetDefault("mounting.cable_hole", 6)
+	v.SetDefault("backing.mode", BackingNone)
+	v.SetDefault("backing.margin", 30)
+	v.SetDefault("backing.corner_radius", 20)
+	v.SetDefault("cut_file.tolerance", 0.05)
+	v.SetDefault("cut_file.keep_curves", true)
+
+	config := Configuration{}
+	err := v.Unmarshal(&config)
+	if err != nil {
+		return Configuration{}, err
+	}
+	if err = config.Validate(); err != nil {
+		return Configuration{}, fmt.Errorf("invalid configuration: %w", err)
+	}
 	return config, nil
 }
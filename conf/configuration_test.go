@@ -0,0 +1,44 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadPricing(t *testing.T) {
+	tests := map[string]struct {
+		file    string
+		want    Pricing
+		wantErr bool
+	}{
+		"prices only": {
+			file: "silicones:\n  - size: 6\n    price: 0.7\ncorner_price: 2.5\n",
+			want: Pricing{Silicones: []Silicone{{SizeMm: 6, PricePerMeter: 0.7}}, CornerPrice: 2.5},
+		},
+		"other sections ignored": {
+			file: "scale: 0\nleds:\n  - name: couleur\n    price: 10\n",
+			want: Pricing{LEDs: []LED{{Name: "couleur", PricePerMeter: 10}}},
+		},
+		"negative price": {
+			file:    "plexis:\n  - name: incolore\n    price: -1\n",
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "prices.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.file), 0o644))
+			got, err := LoadPricing(path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package conf
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var hexColorRegexp = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// priceList is a flattened view of one of the pricing lists, used to compare and check them.
+type priceList struct {
+	name   string
+	unit   string
+	keys   []string
+	prices map[string]float64
+	// details holds the other attributes of each item, in the same order for all the items.
+	details map[string][]detail
+}
+
+// detail is an attribute of an item of a pricing list other than its price, formatted with its unit.
+type detail struct {
+	name  string
+	value string
+}
+
+func newPriceList(name, unit string) priceList {
+	return priceList{name: name, unit: unit, prices: make(map[string]float64), details: make(map[string][]detail)}
+}
+
+func (l priceList) label(key string) string {
+	return l.name + " " + key + l.unit
+}
+
+func (l *priceList) add(key string, price float64, details ...detail) {
+	l.keys = append(l.keys, key)
+	l.prices[key] = price
+	l.details[key] = details
+}
+
+// mm formats a length in millimetres.
+func mm(v float64) string {
+	return fmt.Sprintf("%vmm", v)
+}
+
+func (c Pricing) priceLists() []priceList {
+	silicones := newPriceList("silicone", "mm")
+	for _, s := range c.Silicones {
+		silicones.add(strconv.Itoa(s.SizeMm), s.PricePerMeter, detail{"min bend radius", mm(s.MinBendRadiusMm)})
+	}
+	leds := newPriceList("led", "")
+	for _, l := range c.LEDs {
+		leds.add(l.Name, l.PricePerMeter,
+			detail{"color", l.Color}, detail{"min length", mm(l.MinLengthMm)}, detail{"cut interval", mm(l.CutIntervalMm)})
+	}
+	plexis := newPriceList("plexi", "")
+	for _, p := range c.Plexis {
+		plexis.add(p.Name, p.PricePerMeterSquare,
+			detail{"color", p.Color}, detail{"sheet width", mm(p.SheetWidthMm)}, detail{"sheet height", mm(p.SheetHeightMm)})
+	}
+	controlers := newPriceList("controler", "")
+	for _, ctrl := range c.Controlers {
+		controlers.add(ctrl.Name, ctrl.Price)
+	}
+	powerSupplies := newPriceList("power supply", "A")
+	for _, ps := range c.PowerSupplies {
+		powerSupplies.add(ps.Amp, ps.Price)
+	}
+	return []priceList{silicones, leds, plexis, controlers, powerSupplies}
+}
+
+// Diff lists in a human readable way the differences between two configurations.
+func Diff(old, new Configuration) []string {
+	var changes []string
+	if old.Scale != new.Scale {
+		changes = append(changes, fmt.Sprintf("scale: %v -> %v", old.Scale, new.Scale))
+	}
+	if old.CornerPrice != new.CornerPrice {
+		changes = append(changes, fmt.Sprintf("corner price: %.2f -> %.2f", old.CornerPrice, new.CornerPrice))
+	}
+	if old.FeedPointPrice != new.FeedPointPrice {
+		changes = append(changes, fmt.Sprintf("feed point price: %.2f -> %.2f", old.FeedPointPrice, new.FeedPointPrice))
+	}
+	if old.JumperPricePerMeter != new.JumperPricePerMeter {
+		changes = append(changes, fmt.Sprintf("jumper price: %.2f -> %.2f", old.JumperPricePerMeter, new.JumperPricePerMeter))
+	}
+	if old.CablePricePerMeter != new.CablePricePerMeter {
+		changes = append(changes, fmt.Sprintf("cable price: %.2f -> %.2f", old.CablePricePerMeter, new.CablePricePerMeter))
+	}
+	if old.StandoffPrice != new.StandoffPrice {
+		changes = append(changes, fmt.Sprintf("standoff price: %.2f -> %.2f", old.StandoffPrice, new.StandoffPrice))
+	}
+	if old.HolePrice != new.HolePrice {
+		changes = append(changes, fmt.Sprintf("hole price: %.2f -> %.2f", old.HolePrice, new.HolePrice))
+	}
+	if old.Backing.Mode != new.Backing.Mode {
+		changes = append(changes, fmt.Sprintf("backing: %s -> %s", old.Backing.Mode, new.Backing.Mode))
+	}
+	if old.CutFile != new.CutFile {
+		changes = append(changes, fmt.Sprintf("cut file: tolerance %vmm, keep curves %t -> tolerance %vmm, keep curves %t",
+			old.CutFile.ToleranceMm, old.CutFile.KeepCurves, new.CutFile.ToleranceMm, new.CutFile.KeepCurves))
+	}
+	if old.Wiring.PowerEntry != new.Wiring.PowerEntry {
+		changes = append(changes, fmt.Sprintf("power entry: %s -> %s", old.Wiring.PowerEntry, new.Wiring.PowerEntry))
+	}
+	if old.DesignRules.CornerAngleDeg != new.DesignRules.CornerAngleDeg {
+		changes = append(changes, fmt.Sprintf("corner angle: %v° -> %v°", old.DesignRules.CornerAngleDeg, new.DesignRules.CornerAngleDeg))
+	}
+	for _, length := range []struct {
+		name     string
+		old, new float64
+	}{
+		{"min spacing", old.DesignRules.MinSpacingMm, new.DesignRules.MinSpacingMm},
+		{"join tolerance", old.DesignRules.JoinToleranceMm, new.DesignRules.JoinToleranceMm},
+		{"mounting margin", old.Mounting.MarginMm, new.Mounting.MarginMm},
+		{"standoff hole", old.Mounting.StandoffHoleMm, new.Mounting.StandoffHoleMm},
+		{"cable hole", old.Mounting.CableHoleMm, new.Mounting.CableHoleMm},
+		{"backing margin", old.Backing.MarginMm, new.Backing.MarginMm},
+		{"backing corner radius", old.Backing.CornerRadiusMm, new.Backing.CornerRadiusMm},
+	} {
+		if length.old != length.new {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", length.name, mm(length.old), mm(length.new)))
+		}
+	}
+	for _, text := range []struct {
+		name     string
+		old, new string
+	}{
+		{"company", old.Branding.Company, new.Branding.Company},
+		{"address", old.Branding.Address, new.Branding.Address},
+		{"contact", old.Branding.Contact, new.Branding.Contact},
+		{"currency", old.Branding.Currency, new.Branding.Currency},
+		{"terms", old.Branding.Terms, new.Branding.Terms},
+	} {
+		if text.old != text.new {
+			changes = append(changes, fmt.Sprintf("%s: %q -> %q", text.name, text.old, text.new))
+		}
+	}
+	if old.Branding.ValidityDays != new.Branding.ValidityDays {
+		changes = append(changes, fmt.Sprintf("quote validity: %d -> %d days", old.Branding.ValidityDays, new.Branding.ValidityDays))
+	}
+	oldLists := old.priceLists()
+	for i, newList := range new.priceLists() {
+		oldList := oldLists[i]
+		for _, key := range oldList.keys {
+			if _, ok := newList.prices[key]; !ok {
+				changes = append(changes, fmt.Sprintf("%s: removed", oldList.label(key)))
+			}
+		}
+		for _, key := range newList.keys {
+			oldPrice, ok := oldList.prices[key]
+			switch {
+			case !ok:
+				changes = append(changes, fmt.Sprintf("%s: added at %.2f", newList.label(key), newList.prices[key]))
+			case oldPrice != newList.prices[key]:
+				changes = append(changes, fmt.Sprintf("%s: %.2f -> %.2f", newList.label(key), oldPrice, newList.prices[key]))
+			}
+			if !ok {
+				continue
+			}
+			for i, d := range newList.details[key] {
+				if oldDetail := oldList.details[key][i]; oldDetail != d {
+					changes = append(changes, fmt.Sprintf("%s %s: %s -> %s", newList.label(key), d.name, oldDetail.value, d.value))
+				}
+			}
+		}
+	}
+	return changes
+}
+
+// Validate checks that the configuration can be used to compute prices.
+func (c Configuration) Validate() error {
+	if c.Scale <= 0 {
+		return fmt.Errorf("scale must be positive, got %v", c.Scale)
+	}
+	if err := c.Pricing.Validate(); err != nil {
+		return err
+	}
+	if c.DesignRules.MinSpacingMm < 0 {
+		return fmt.Errorf("minimum spacing must not be negative, got %v", c.DesignRules.MinSpacingMm)
+	}
+	if c.DesignRules.CornerAngleDeg < 0 || c.DesignRules.CornerAngleDeg >= 180 {
+		return fmt.Errorf("corner angle must be between 0 and 180 degrees, got %v", c.DesignRules.CornerAngleDeg)
+	}
+	if c.DesignRules.JoinToleranceMm < 0 {
+		return fmt.Errorf("join tolerance must not be negative, got %v", c.DesignRules.JoinToleranceMm)
+	}
+	if c.Mounting.MarginMm < 0 || c.Mounting.StandoffHoleMm < 0 || c.Mounting.CableHoleMm < 0 {
+		return fmt.Errorf("mounting margin and hole diameters must not be negative, got %v, %v and %v",
+			c.Mounting.MarginMm, c.Mounting.StandoffHoleMm, c.Mounting.CableHoleMm)
+	}
+	if c.Backing.Mode != "" && !slices.Contains(BackingModes, c.Backing.Mode) {
+		return fmt.Errorf("unknown backing mode %q, expected one of %s", c.Backing.Mode, strings.Join(BackingModes, ", "))
+	}
+	if c.Backing.MarginMm < 0 || c.Backing.CornerRadiusMm < 0 {
+		return fmt.Errorf("backing margin and corner radius must not be negative, got %v and %v",
+			c.Backing.MarginMm, c.Backing.CornerRadiusMm)
+	}
+	if c.CutFile.ToleranceMm < 0 {
+		return fmt.Errorf("cut file tolerance must not be negative, got %v", c.CutFile.ToleranceMm)
+	}
+	if c.Wiring.PowerEntry != "" && !slices.Contains(PowerEntries, c.Wiring.PowerEntry) {
+		return fmt.Errorf("unknown power entry %q, expected one of %s", c.Wiring.PowerEntry, strings.Join(PowerEntries, ", "))
+	}
+	if c.Branding.ValidityDays < 0 {
+		return fmt.Errorf("quote validity must not be negative, got %d days", c.Branding.ValidityDays)
+	}
+	return nil
+}
+
+// Validate checks that the prices and the items of the pricing lists can be used to compute prices.
+func (c Pricing) Validate() error {
+	for _, s := range c.Silicones {
+		if s.SizeMm <= 0 {
+			return fmt.Errorf("silicone size must be positive, got %d", s.SizeMm)
+		}
+		if s.MinBendRadiusMm < 0 {
+			return fmt.Errorf("silicone %dmm minimum bend radius must not be negative, got %v", s.SizeMm, s.MinBendRadiusMm)
+		}
+	}
+	for _, l := range c.LEDs {
+		if l.Color != "" && !hexColorRegexp.MatchString(l.Color) {
+			return fmt.Errorf("led %s color %q is not formatted as #rrggbb", l.Name, l.Color)
+		}
+		if l.MinLengthMm < 0 {
+			return fmt.Errorf("led %s minimum length must not be negative, got %v", l.Name, l.MinLengthMm)
+		}
+		if l.CutIntervalMm < 0 {
+			return fmt.Errorf("led %s cut interval must not be negative, got %v", l.Name, l.CutIntervalMm)
+		}
+	}
+	for _, p := range c.Plexis {
+		if p.Color != "" && !hexColorRegexp.MatchString(p.Color) {
+			return fmt.Errorf("plexi %s color %q is not formatted as #rrggbb", p.Name, p.Color)
+		}
+		if p.SheetWidthMm < 0 || p.SheetHeightMm < 0 {
+			return fmt.Errorf("plexi %s sheet dimensions must not be negative, got %v x %v", p.Name, p.SheetWidthMm, p.SheetHeightMm)
+		}
+	}
+	if c.CornerPrice < 0 {
+		return fmt.Errorf("corner price must not be negative, got %v", c.CornerPrice)
+	}
+	if c.FeedPointPrice < 0 || c.JumperPricePerMeter < 0 {
+		return fmt.Errorf("feed point and jumper prices must not be negative, got %v and %v", c.FeedPointPrice, c.JumperPricePerMeter)
+	}
+	if c.CablePricePerMeter < 0 {
+		return fmt.Errorf("cable price must not be negative, got %v", c.CablePricePerMeter)
+	}
+	if c.StandoffPrice < 0 || c.HolePrice < 0 {
+		return fmt.Errorf("standoff and hole prices must not be negative, got %v and %v", c.StandoffPrice, c.HolePrice)
+	}
+	for _, list := range c.priceLists() {
+		if len(list.prices) != len(list.keys) {
+			return fmt.Errorf("%s list contains duplicates", list.name)
+		}
+		for _, key := range list.keys {
+			if key == "" {
+				return fmt.Errorf("%s list contains an item without name", list.name)
+			}
+			if list.prices[key] < 0 {
+				return fmt.Errorf("%s has a negative price", list.label(key))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package conf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfiguration() Configuration {
+	return Configuration{
+		Scale: 2834.6457,
+		Pricing: Pricing{
+			Silicones:     []Silicone{{SizeMm: 6, PricePerMeter: 0.7}},
+			Plexis:        []Plexi{{Name: "incolore", PricePerMeterSquare: 50}},
+			PowerSupplies: []PowerSupply{{Amp: "5", Price: 5.55}},
+		},
+	}
+}
+
+func Test_Diff(t *testing.T) {
+	old := testConfiguration()
+	new := testConfiguration().Clone()
+	new.Silicones[0].PricePerMeter = 0.75
+	new.Plexis = append(new.Plexis, Plexi{Name: "noir", PricePerMeterSquare: 60.27})
+	new.PowerSupplies = nil
+	new.CornerPrice = 2.5
+	new.JumperPricePerMeter = 1.2
+
+	assert.Equal(t, []string{
+		"corner price: 0.00 -> 2.50",
+		"jumper price: 0.00 -> 1.20",
+		"silicone 6mm: 0.70 -> 0.75",
+		"plexi noir: added at 60.27",
+		"power supply 5A: removed",
+	}, Diff(old, new))
+	assert.Equal(t, 0.7, old.Silicones[0].PricePerMeter)
+	assert.Empty(t, Diff(old, old))
+}
+
+func Test_Diff_pricedAttributes(t *testing.T) {
+	tests := map[string]struct {
+		update func(c *Configuration)
+		want   []string
+	}{
+		"led cut interval and min length": {
+			update: func(c *Configuration) {
+				c.LEDs[0].CutIntervalMm = 50
+				c.LEDs[0].MinLengthMm = 25
+			},
+			want: []string{"led couleur min length: 0mm -> 25mm", "led couleur cut interval: 0mm -> 50mm"},
+		},
+		"silicone min bend radius": {
+			update: func(c *Configuration) { c.Silicones[0].MinBendRadiusMm = 15 },
+			want:   []string{"silicone 6mm min bend radius: 0mm -> 15mm"},
+		},
+		"plexi sheet": {
+			update: func(c *Configuration) { c.Plexis[0].SheetWidthMm = 3000 },
+			want:   []string{"plexi incolore sheet width: 0mm -> 3000mm"},
+		},
+		"design rules": {
+			update: func(c *Configuration) {
+				c.DesignRules.MinSpacingMm = 3
+				c.DesignRules.JoinToleranceMm = 2
+			},
+			want: []string{"min spacing: 0mm -> 3mm", "join tolerance: 0mm -> 2mm"},
+		},
+		"mounting": {
+			update: func(c *Configuration) { c.Mounting = Mounting{MarginMm: 20, StandoffHoleMm: 8, CableHoleMm: 6} },
+			want:   []string{"mounting margin: 0mm -> 20mm", "standoff hole: 0mm -> 8mm", "cable hole: 0mm -> 6mm"},
+		},
+		"backing": {
+			update: func(c *Configuration) { c.Backing = Backing{MarginMm: 10, CornerRadiusMm: 5} },
+			want:   []string{"backing margin: 0mm -> 10mm", "backing corner radius: 0mm -> 5mm"},
+		},
+		"branding": {
+			update: func(c *Configuration) { c.Branding = Branding{Company: "Neon & Co", ValidityDays: 30} },
+			want:   []string{`company: "" -> "Neon & Co"`, "quote validity: 0 -> 30 days"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			old := testConfiguration()
+			old.LEDs = []LED{{Name: "couleur", PricePerMeter: 10}}
+			new := old.Clone()
+			tt.update(&new)
+			assert.Equal(t, tt.want, Diff(old, new))
+		})
+	}
+}
+
+func Test_Configuration_Validate(t *testing.T) {
+	tests := map[string]struct {
+		update  func(c *Configuration)
+		wantErr bool
+	}{
+		"valid": {
+			update: func(c *Configuration) {},
+		},
+		"zero scale": {
+			update:  func(c *Configuration) { c.Scale = 0 },
+			wantErr: true,
+		},
+		"negative price": {
+			update:  func(c *Configuration) { c.Plexis[0].PricePerMeterSquare = -1 },
+			wantErr: true,
+		},
+		"duplicate": {
+			update:  func(c *Configuration) { c.Silicones = append(c.Silicones, Silicone{SizeMm: 6}) },
+			wantErr: true,
+		},
+		"missing name": {
+			update:  func(c *Configuration) { c.LEDs = append(c.LEDs, LED{PricePerMeter: 1}) },
+			wantErr: true,
+		},
+		"led color": {
+			update: func(c *Configuration) { c.LEDs = append(c.LEDs, LED{Name: "couleur", Color: "#FF00aa"}) },
+		},
+		"invalid plexi color": {
+			update:  func(c *Configuration) { c.Plexis[0].Color = "red" },
+			wantErr: true,
+		},
+		"negative validity": {
+			update:  func(c *Configuration) { c.Branding.ValidityDays = -1 },
+			wantErr: true,
+		},
+		"negative bend radius": {
+			update:  func(c *Configuration) { c.Silicones[0].MinBendRadiusMm = -1 },
+			wantErr: true,
+		},
+		"negative cut interval": {
+			update:  func(c *Configuration) { c.LEDs = append(c.LEDs, LED{Name: "couleur", CutIntervalMm: -25}) },
+			wantErr: true,
+		},
+		"negative plexi sheet": {
+			update:  func(c *Configuration) { c.Plexis[0].SheetWidthMm = -1 },
+			wantErr: true,
+		},
+		"flat corner angle": {
+			update:  func(c *Configuration) { c.DesignRules.CornerAngleDeg = 180 },
+			wantErr: true,
+		},
+		"negative corner price": {
+			update:  func(c *Configuration) { c.CornerPrice = -1 },
+			wantErr: true,
+		},
+		"negative feed point price": {
+			update:  func(c *Configuration) { c.FeedPointPrice = -1 },
+			wantErr: true,
+		},
+		"power entry": {
+			update: func(c *Configuration) { c.Wiring.PowerEntry = PowerEntryTopLeft },
+		},
+		"unknown backing mode": {
+			update:  func(c *Configuration) { c.Backing.Mode = "oval" },
+			wantErr: true,
+		},
+		"contour backing": {
+			update: func(c *Configuration) { c.Backing = Backing{Mode: BackingContour, MarginMm: 30} },
+		},
+		"negative cut file tolerance": {
+			update:  func(c *Configuration) { c.CutFile.ToleranceMm = -0.1 },
+			wantErr: true,
+		},
+		"unknown power entry": {
+			update:  func(c *Configuration) { c.Wiring.PowerEntry = "middle" },
+			wantErr: true,
+		},
+		"negative hole price": {
+			update:  func(c *Configuration) { c.HolePrice = -1 },
+			wantErr: true,
+		},
+		"negative mounting margin": {
+			update:  func(c *Configuration) { c.Mounting.MarginMm = -1 },
+			wantErr: true,
+		},
+		"negative cable price": {
+			update:  func(c *Configuration) { c.CablePricePerMeter = -1 },
+			wantErr: true,
+		},
+		"negative join tolerance": {
+			update:  func(c *Configuration) { c.DesignRules.JoinToleranceMm = -1 },
+			wantErr: true,
+		},
+		"negative spacing": {
+			update:  func(c *Configuration) { c.DesignRules.MinSpacingMm = -1 },
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := testConfiguration()
+			tt.update(&c)
+			if tt.wantErr {
+				assert.Error(t, c.Validate())
+			} else {
+				assert.NoError(t, c.Validate())
+			}
+		})
+	}
+}
+
+func Test_Store_Update(t *testing.T) {
+	store := NewStore(testConfiguration())
+	before := store.Get()
+
+	err := store.Update(func(c *Configuration) error {
+		c.Silicones[0].PricePerMeter = 10
+		return errors.New("rejected")
+	})
+	assert.Error(t, err)
+	assert.Equal(t, testConfiguration(), store.Get())
+
+	err = store.Update(func(c *Configuration) error {
+		c.Silicones[0].PricePerMeter = 10
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, store.Get().Silicones[0].PricePerMeter)
+	assert.Equal(t, 0.7, before.Silicones[0].PricePerMeter)
+}
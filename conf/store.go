@@ -0,0 +1,57 @@
+package conf
+
+import (
+	"slices"
+	"sync"
+)
+
+// Store holds the running configuration and allows it to be swapped atomically.
+// A configuration returned by Get is never modified afterwards, updates always
+// work on a copy.
+type Store struct {
+	mu     sync.RWMutex
+	config Configuration
+}
+
+func NewStore(config Configuration) *Store {
+	return &Store{config: config}
+}
+
+// Get returns the current configuration.
+func (s *Store) Get() Configuration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config
+}
+
+// Swap replaces the current configuration and returns the previous one.
+func (s *Store) Swap(config Configuration) Configuration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.config
+	s.config = config
+	return previous
+}
+
+// Update applies fn on a copy of the current configuration and stores the result
+// if fn succeeds.
+func (s *Store) Update(fn func(config *Configuration) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	config := s.config.Clone()
+	if err := fn(&config); err != nil {
+		return err
+	}
+	s.config = config
+	return nil
+}
+
+// Clone returns a deep copy of the configuration.
+func (c Configuration) Clone() Configuration {
+	c.Silicones = slices.Clone(c.Silicones)
+	c.LEDs = slices.Clone(c.LEDs)
+	c.Plexis = slices.Clone(c.Plexis)
+	c.Controlers = slices.Clone(c.Controlers)
+	c.PowerSupplies = slices.Clone(c.PowerSupplies)
+	return c
+}
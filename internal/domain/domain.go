@@ -3,12 +3,113 @@ package domain
 import "math"
 
 type Size struct {
-	Length   float64
-	LengthPx float64
-	Height   float64
-	Width    float64
+	Length   float64 `json:"length_mm"`
+	LengthPx float64 `json:"length_px"`
+	Height   float64 `json:"height_mm"`
+	Width    float64 `json:"width_mm"`
+	// Strokes is the number of strokes of the group, each subpath being a stroke.
+	Strokes int `json:"strokes,omitempty"`
+	// Corners is the number of sharp corners of the strokes of the group.
+	Corners int `json:"corners,omitempty"`
+	// StrokeLengths holds the length of each stroke of the group in mm.
+	StrokeLengths []float64 `json:"stroke_lengths_mm,omitempty"`
+	// FeedPoints is the number of runs of strokes fed by their own LED strip.
+	FeedPoints int `json:"feed_points,omitempty"`
+	// JumperLength is the length in mm of the cables linking the runs behind the backing.
+	JumperLength float64 `json:"jumper_length_mm,omitempty"`
+	// CableLength is the length in mm of the cables from the feed points to the controller.
+	CableLength float64 `json:"cable_length_mm,omitempty"`
+	// Standoffs and CableHoles are the numbers of holes drilled in the backing for the standoffs
+	// mounting it and for the cables.
+	Standoffs  int `json:"standoffs,omitempty"`
+	CableHoles int `json:"cable_holes,omitempty"`
+}
+
+// StripCut is the LED strip bought for a stroke.
+type StripCut struct {
+	LengthMm float64 `json:"length_mm"`
+	// PurchasedMm is the length of the strip, cut at the first cut point after the end of the stroke.
+	PurchasedMm float64 `json:"purchased_mm"`
+	WasteMm     float64 `json:"waste_mm"`
+}
+
+type LayerPrice struct {
+	SiliconePrice float64 `json:"silicone_price"`
+	LEDPrice      float64 `json:"led_price"`
+	PlexiPrice    float64 `json:"plexi_price"`
+	// LabourPrice is the price of the work on the sharp corners.
+	LabourPrice float64 `json:"labour_price,omitempty"`
+	// FeedPrice is the price of the work on the feed points.
+	FeedPrice   float64 `json:"feed_price,omitempty"`
+	JumperPrice float64 `json:"jumper_price,omitempty"`
+	CablePrice  float64 `json:"cable_price,omitempty"`
+	// MountingPrice is the price of the standoffs, DrillingPrice the one of drilling their holes
+	// and the cable holes.
+	MountingPrice float64 `json:"mounting_price,omitempty"`
+	DrillingPrice float64 `json:"drilling_price,omitempty"`
+}
+
+// Total returns the sum of the prices of the layer.
+func (lp LayerPrice) Total() float64 {
+	return Round(lp.SiliconePrice + lp.LEDPrice + lp.PlexiPrice + lp.LabourPrice + lp.FeedPrice + lp.JumperPrice + lp.CablePrice +
+		lp.MountingPrice + lp.DrillingPrice)
+}
+
+// Price holds the price of each layer, by group id.
+type Price map[string]LayerPrice
+
+// Total returns the sum of the prices of all layers.
+func (p Price) Total() float64 {
+	var total float64
+	for _, lp := range p {
+		total += lp.Total()
+	}
+	return Round(total)
 }
 
 func Round(n float64) float64 {
 	return math.Round(n*100) / 100
 }
+
+// BendViolation is a curve of a stroke bent tighter than the silicone of its group can be.
+type BendViolation struct {
+	// X and Y locate the tightest point of the curve in the coordinates of the svg file.
+	X           float64 `json:"x"`
+	Y           float64 `json:"y"`
+	RadiusMm    float64 `json:"radius_mm"`
+	MinRadiusMm float64 `json:"min_radius_mm"`
+	// RecommendedSizeMm is the size of the largest silicone that can be bent as tight, 0 if none can.
+	RecommendedSizeMm int `json:"recommended_size_mm,omitempty"`
+}
+
+// Severity tells whether a design issue prevents building the design.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a design rule broken by a design.
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Group    string   `json:"group,omitempty"`
+	// X and Y locate the issue in the coordinates of the svg file.
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	// Value is the measure breaking the rule and Limit the one required by the rule, in mm.
+	Value   float64 `json:"value_mm"`
+	Limit   float64 `json:"limit_mm"`
+	Message string  `json:"message"`
+}
+
+// QuoteLine is a priced item of a quote.
+type QuoteLine struct {
+	Group       string  `json:"group"`
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit"`
+	UnitPrice   float64 `json:"unit_price"`
+	Amount      float64 `json:"amount"`
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"theo303/neon-pricer/conf"
+)
+
+// Quote is the result of the pricing of a design, kept to be consulted later.
+type Quote struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	FileName  string    `json:"file_name"`
+	// Hash is the hex encoded sha256 of the svg file.
+	Hash  string `json:"hash"`
+	Plexi string `json:"plexi"`
+	// Config is the configuration used to compute the prices.
+	Config conf.Configuration `json:"config"`
+	Sizes  map[string]Size    `json:"sizes"`
+	Prices Price              `json:"prices"`
+	// Quantity is the number of identical signs, quotes saved before it was asked have none.
+	Quantity int `json:"quantity,omitempty"`
+	// Total is the price of all the signs.
+	Total float64 `json:"total"`
+	// SVG is the original file, it is stored and served apart from the quote.
+	SVG []byte `json:"-"`
+}
+
+// Copies returns the number of identical signs of the quote, at least 1.
+func (q Quote) Copies() int {
+	if q.Quantity < 1 {
+		return 1
+	}
+	return q.Quantity
+}
@@ -0,0 +1,569 @@
+package dxf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"theo303/neon-pricer/internal/svg"
+)
+
+// binarySentinel starts binary DXF files.
+const binarySentinel = "AutoCAD Binary DXF"
+
+// splineSamples is the number of lines each span of a spline is split into.
+const splineSamples = 16
+
+// maxSplineDegree is the highest degree of the splines drawn by CAD software.
+const maxSplineDegree = 11
+
+// ErrBinary is returned when reading a binary DXF file, only ascii ones are read.
+var ErrBinary = errors.New("binary DXF files are not supported")
+
+// mmPerUnit are the millimetres in the drawing units of the $INSUNITS header variable,
+// unitless drawings being read in millimetres.
+var mmPerUnit = map[int]float64{0: 1, 1: 25.4, 2: 304.8, 4: 1, 5: 10, 6: 1000}
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// IsDXF reports whether data looks like a DXF file: a binary one, or an ascii one starting
+// with a section or a comment.
+func IsDXF(data []byte) bool {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if bytes.HasPrefix(data, []byte(binarySentinel)) {
+		return true
+	}
+	lines := bytes.SplitN(data, []byte("\n"), 3)
+	if len(lines) < 2 {
+		return false
+	}
+	code, value := string(bytes.TrimSpace(lines[0])), string(bytes.TrimSpace(lines[1]))
+	return code == "999" || (code == "0" && value == "SECTION")
+}
+
+// RetrieveForms retrieves the forms of the DXF source, see Parse.
+func RetrieveForms(source io.Reader, layer string, scale float64) (map[string][]svg.Form, error) {
+	parsed, err := Parse(source, layer, scale, svg.Limits{})
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Forms, nil
+}
+
+// Parse retrieves the forms drawn by the entities of the DXF source, grouped by layer, and reports
+// the entities it skips. Only the entities of layer are retrieved, unless it is empty. The drawing
+// units are converted to svg units, scale being the number of svg units in a metre, and the y axis
+// is flipped to point down as in svg. It fails with svg.ErrLimitExceeded as soon as the source exceeds
+// the limits, MaxElements bounding the number of entities.
+func Parse(source io.Reader, layer string, scale float64, limits svg.Limits) (svg.Parsed, error) {
+	raw, err := io.ReadAll(source)
+	if err != nil {
+		return svg.Parsed{}, fmt.Errorf("reading dxf file: %w", err)
+	}
+	raw = bytes.TrimPrefix(raw, utf8BOM)
+	if bytes.HasPrefix(raw, []byte(binarySentinel)) {
+		return svg.Parsed{}, ErrBinary
+	}
+	pairs, err := readPairs(raw)
+	if err != nil {
+		return svg.Parsed{}, fmt.Errorf("parsing dxf file: %w", err)
+	}
+	units, entities := readSections(pairs)
+	mm, ok := mmPerUnit[units]
+	if !ok {
+		return svg.Parsed{}, fmt.Errorf("unsupported drawing units %d", units)
+	}
+	if limits.MaxElements > 0 && len(entities) > limits.MaxElements {
+		return svg.Parsed{}, fmt.Errorf("more than %d entities: %w", limits.MaxElements, svg.ErrLimitExceeded)
+	}
+
+	r := reader{k: mm * scale / 1000, limits: limits}
+	parsed := svg.Parsed{Forms: make(map[string][]svg.Form)}
+	for i, e := range entities {
+		group := e.layer()
+		if layer != "" && group != layer {
+			continue
+		}
+		form, reason, err := r.form(e)
+		if err != nil {
+			return svg.Parsed{}, fmt.Errorf("reading entity n %d (%s) of layer %s: %w", i, e.kind, group, err)
+		}
+		if reason != "" {
+			parsed.Skipped = append(parsed.Skipped, svg.Skipped{Group: group, Element: e.kind, ID: e.value(5), Reason: reason})
+			continue
+		}
+		if form != nil {
+			parsed.Forms[group] = append(parsed.Forms[group], form)
+		}
+	}
+	return parsed, nil
+}
+
+// pair is a group code and its value, numbers being parsed.
+type pair struct {
+	code   int
+	value  string
+	number float64
+}
+
+// numeric reports whether the values of the group code are numbers.
+func numeric(code int) bool {
+	return (code >= 10 && code <= 99) || (code >= 110 && code <= 149) || (code >= 210 && code <= 239)
+}
+
+// readPairs reads the group codes and values of an ascii DXF file, each on its own line.
+func readPairs(raw []byte) ([]pair, error) {
+	lines := strings.Split(string(raw), "\n")
+	if len(lines)%2 == 1 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines)%2 == 1 {
+		return nil, fmt.Errorf("missing value of the last group code")
+	}
+	pairs := make([]pair, 0, len(lines)/2)
+	for i := 0; i < len(lines); i += 2 {
+		code, err := strconv.Atoi(strings.TrimSpace(lines[i]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid group code %q", i+1, strings.TrimSpace(lines[i]))
+		}
+		p := pair{code: code, value: strings.TrimSpace(lines[i+1])}
+		if numeric(code) {
+			p.number, err = strconv.ParseFloat(p.value, 64)
+			if err != nil || math.IsNaN(p.number) || math.IsInf(p.number, 0) {
+				return nil, fmt.Errorf("line %d: invalid number %q for group code %d", i+2, p.value, code)
+			}
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, nil
+}
+
+// entity is a drawn object of the ENTITIES section.
+type entity struct {
+	kind  string
+	pairs []pair
+	// vertices are the VERTEX entities following a POLYLINE.
+	vertices []entity
+}
+
+// value returns the value of the first group code of the entity, or an empty string.
+func (e entity) value(code int) string {
+	for _, p := range e.pairs {
+		if p.code == code {
+			return p.value
+		}
+	}
+	return ""
+}
+
+// number returns the number of the first group code of the entity, or def without it.
+func (e entity) number(code int, def float64) float64 {
+	for _, p := range e.pairs {
+		if p.code == code {
+			return p.number
+		}
+	}
+	return def
+}
+
+// numbers returns the numbers of all the group codes of the entity, in order.
+func (e entity) numbers(code int) []float64 {
+	var numbers []float64
+	for _, p := range e.pairs {
+		if p.code == code {
+			numbers = append(numbers, p.number)
+		}
+	}
+	return numbers
+}
+
+// points returns the points of the entity, their x and y being given by the group codes
+// xCode and xCode+10, each x starting a new point.
+func (e entity) points(xCode int) []svg.Point {
+	var points []svg.Point
+	for _, p := range e.pairs {
+		switch {
+		case p.code == xCode:
+			points = append(points, svg.Point{X: p.number})
+		case p.code == xCode+10 && len(points) > 0:
+			points[len(points)-1].Y = p.number
+		}
+	}
+	return points
+}
+
+func (e entity) layer() string {
+	if layer := e.value(8); layer != "" {
+		return layer
+	}
+	return "0"
+}
+
+// flags returns the bit flags of group code 70.
+func (e entity) flags() int {
+	return int(e.number(70, 0))
+}
+
+// readSections returns the drawing units of the header and the entities of the pairs, the VERTEX
+// entities of polylines being held by them.
+func readSections(pairs []pair) (int, []entity) {
+	var units int
+	var section, variable string
+	var flat []entity
+	for i, p := range pairs {
+		if p.code == 0 {
+			switch p.value {
+			case "SECTION":
+				section = ""
+				if i+1 < len(pairs) && pairs[i+1].code == 2 {
+					section = pairs[i+1].value
+				}
+			case "ENDSEC":
+				section = ""
+			default:
+				if section == "ENTITIES" {
+					flat = append(flat, entity{kind: p.value})
+				}
+			}
+			continue
+		}
+		switch section {
+		case "HEADER":
+			if p.code == 9 {
+				variable = p.value
+			} else if variable == "$INSUNITS" && p.code == 70 {
+				units, _ = strconv.Atoi(p.value)
+			}
+		case "ENTITIES":
+			if len(flat) > 0 {
+				flat[len(flat)-1].pairs = append(flat[len(flat)-1].pairs, p)
+			}
+		}
+	}
+
+	entities := make([]entity, 0, len(flat))
+	polyline := -1
+	for _, e := range flat {
+		switch {
+		case e.kind == "VERTEX" && polyline >= 0:
+			entities[polyline].vertices = append(entities[polyline].vertices, e)
+		case e.kind == "SEQEND":
+			polyline = -1
+		default:
+			polyline = -1
+			if e.kind == "POLYLINE" {
+				polyline = len(entities)
+			}
+			entities = append(entities, e)
+		}
+	}
+	return units, entities
+}
+
+// reader converts entities to forms, keeping track of the resources used.
+type reader struct {
+	// k is the number of svg units in a drawing unit.
+	k            float64
+	limits       svg.Limits
+	pathCommands int
+}
+
+// frame converts the coordinates of an entity to svg units.
+type frame struct {
+	k float64
+	// mirrored is set for the entities drawn in a coordinate system seen from below, as mirrored
+	// ones, their x axis pointing the other way.
+	mirrored bool
+}
+
+func (f frame) point(x, y float64) svg.Point {
+	if f.mirrored {
+		x = -x
+	}
+	return svg.Point{X: x * f.k, Y: -y * f.k}
+}
+
+// sweep returns the svg sweep flag of the arcs turning counterclockwise, or clockwise if ccw is false,
+// in the coordinates of the entity. The y axis pointing down in svg, counterclockwise arcs turn the
+// negative way, unless the x axis is mirrored too.
+func (f frame) sweep(ccw bool) float64 {
+	if ccw != f.mirrored {
+		return 0
+	}
+	return 1
+}
+
+// ocs returns the frame of the entities drawn in their own coordinate system, given by their
+// extrusion direction. Only the drawings seen from above or below are read.
+func (r *reader) ocs(e entity) frame {
+	return frame{k: r.k, mirrored: e.number(230, 1) < 0}
+}
+
+// form returns the form drawn by the entity, or the reason it is skipped.
+func (r *reader) form(e entity) (svg.Form, string, error) {
+	wcs := frame{k: r.k}
+	switch e.kind {
+	case "LINE":
+		return svg.NewLine(wcs.point(e.number(10, 0), e.number(20, 0)), wcs.point(e.number(11, 0), e.number(21, 0))), "", nil
+	case "CIRCLE":
+		f := r.ocs(e)
+		radius := e.number(40, 0)
+		if radius <= 0 {
+			return nil, "", fmt.Errorf("invalid radius %g", radius)
+		}
+		return svg.NewCircle(f.point(e.number(10, 0), e.number(20, 0)), radius*r.k), "", nil
+	case "ARC":
+		f := r.ocs(e)
+		cx, cy, radius := e.number(10, 0), e.number(20, 0), e.number(40, 0)
+		if radius <= 0 {
+			return nil, "", fmt.Errorf("invalid radius %g", radius)
+		}
+		start := e.number(50, 0) * math.Pi / 180
+		point := func(t float64) svg.Point { return f.point(cx+radius*math.Cos(t), cy+radius*math.Sin(t)) }
+		path := arcPath(point, start, turn(e.number(51, 360)*math.Pi/180-start), radius*r.k, radius*r.k, 0, f.sweep(true))
+		return r.path(path)
+	case "ELLIPSE":
+		cx, cy := e.number(10, 0), e.number(20, 0)
+		mx, my, ratio := e.number(11, 0), e.number(21, 0), e.number(40, 1)
+		major := math.Hypot(mx, my)
+		if major == 0 || ratio <= 0 {
+			return nil, "", fmt.Errorf("invalid axes (%g, %g) and ratio %g", mx, my, ratio)
+		}
+		// the minor axis is a quarter turn counterclockwise from the major one around the extrusion direction.
+		ccw := e.number(230, 1) >= 0
+		nx, ny := -my*ratio, mx*ratio
+		if !ccw {
+			nx, ny = -nx, -ny
+		}
+		point := func(t float64) svg.Point {
+			cos, sin := math.Cos(t), math.Sin(t)
+			return wcs.point(cx+cos*mx+sin*nx, cy+cos*my+sin*ny)
+		}
+		start := e.number(41, 0)
+		rotation := -math.Atan2(my, mx) * 180 / math.Pi
+		path := arcPath(point, start, turn(e.number(42, 2*math.Pi)-start), major*r.k, major*ratio*r.k, rotation, wcs.sweep(ccw))
+		return r.path(path)
+	case "LWPOLYLINE":
+		f := r.ocs(e)
+		var vertices []vertex
+		for _, p := range e.pairs {
+			switch {
+			case p.code == 10:
+				vertices = append(vertices, vertex{x: p.number})
+			case len(vertices) == 0:
+			case p.code == 20:
+				vertices[len(vertices)-1].y = p.number
+			case p.code == 42:
+				vertices[len(vertices)-1].bulge = p.number
+			}
+		}
+		return r.polyline(f, vertices, e.flags()&1 != 0)
+	case "POLYLINE":
+		if e.flags()&(16|64) != 0 {
+			return nil, "meshes are not measured", nil
+		}
+		f := r.ocs(e)
+		if e.flags()&8 != 0 {
+			f = wcs
+		}
+		var vertices []vertex
+		for _, v := range e.vertices {
+			// the control points of spline fitted polylines are not on the curve.
+			if v.flags()&16 != 0 {
+				continue
+			}
+			vertices = append(vertices, vertex{x: v.number(10, 0), y: v.number(20, 0), bulge: v.number(42, 0)})
+		}
+		return r.polyline(f, vertices, e.flags()&1 != 0)
+	case "SPLINE":
+		points, err := splinePoints(e)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(points) < 2 {
+			return nil, "", nil
+		}
+		commands := make([]svg.Path, 0, len(points))
+		for i, p := range points {
+			command := svg.Path{Command: 'L', Parameters: []float64{p.X * r.k, -p.Y * r.k}}
+			if i == 0 {
+				command.Command = 'M'
+			}
+			commands = append(commands, command)
+		}
+		return r.path(commands)
+	case "INSERT":
+		return nil, "block references are not measured", nil
+	}
+	return nil, "unsupported entity", nil
+}
+
+// path links the commands into a path, failing if the commands of all paths exceed the limits.
+func (r *reader) path(commands []svg.Path) (svg.Form, string, error) {
+	r.pathCommands += len(commands)
+	if r.limits.MaxPathCommands > 0 && r.pathCommands > r.limits.MaxPathCommands {
+		return nil, "", fmt.Errorf("more than %d path commands: %w", r.limits.MaxPathCommands, svg.ErrLimitExceeded)
+	}
+	return svg.NewPath(commands...), "", nil
+}
+
+// turn returns the angle in radians in (0, 2π], a whole turn for the angles of closed curves.
+func turn(angle float64) float64 {
+	angle = math.Mod(angle, 2*math.Pi)
+	if angle <= 1e-12 {
+		angle += 2 * math.Pi
+	}
+	return angle
+}
+
+// arcPath returns the commands of the elliptical arc going through point(t) for t from start over
+// sweep radians, split in arcs of at most a quarter turn.
+func arcPath(point func(t float64) svg.Point, start, sweep, rx, ry, rotation, flag float64) []svg.Path {
+	p := point(start)
+	commands := []svg.Path{{Command: 'M', Parameters: []float64{p.X, p.Y}}}
+	n := int(math.Ceil(sweep / (math.Pi / 2)))
+	for i := 1; i <= n; i++ {
+		p = point(start + sweep*float64(i)/float64(n))
+		commands = append(commands, svg.Path{Command: 'A', Parameters: []float64{rx, ry, rotation, 0, flag, p.X, p.Y}})
+	}
+	return commands
+}
+
+// vertex is a point of a polyline, and the bulge of the arc going to the next one: the tangent of a
+// quarter of its angle, positive if it turns counterclockwise, 0 for a line.
+type vertex struct {
+	x, y, bulge float64
+}
+
+// polyline returns the path going through the vertices, closed if closed is set.
+func (r *reader) polyline(f frame, vertices []vertex, closed bool) (svg.Form, string, error) {
+	if len(vertices) < 2 {
+		return nil, "", nil
+	}
+	first := f.point(vertices[0].x, vertices[0].y)
+	commands := []svg.Path{{Command: 'M', Parameters: []float64{first.X, first.Y}}}
+	last := len(vertices) - 1
+	if closed {
+		last++
+	}
+	for i := 0; i < last; i++ {
+		from, to := vertices[i], vertices[(i+1)%len(vertices)]
+		start, end := f.point(from.x, from.y), f.point(to.x, to.y)
+		chord := math.Hypot(end.X-start.X, end.Y-start.Y)
+		switch {
+		case chord == 0:
+		case from.bulge == 0:
+			commands = append(commands, svg.Path{Command: 'L', Parameters: []float64{end.X, end.Y}})
+		default:
+			b := math.Abs(from.bulge)
+			radius := chord * (1 + b*b) / (4 * b)
+			large := 0.0
+			if b > 1 {
+				large = 1
+			}
+			commands = append(commands, svg.Path{
+				Command:    'A',
+				Parameters: []float64{radius, radius, 0, large, f.sweep(from.bulge > 0), end.X, end.Y},
+			})
+		}
+	}
+	if closed {
+		commands = append(commands, svg.Path{Command: 'Z'})
+	}
+	return r.path(commands)
+}
+
+// splinePoints returns the points of the spline in drawing units, its control points being sampled
+// on each span of its knots, or its fit points without control points.
+func splinePoints(e entity) ([]svg.Point, error) {
+	controls := e.points(10)
+	if len(controls) == 0 {
+		return e.points(11), nil
+	}
+	degree := int(e.number(71, 3))
+	if degree < 1 || degree > maxSplineDegree {
+		return nil, fmt.Errorf("invalid spline degree %d", degree)
+	}
+	n := len(controls)
+	degree = min(degree, n-1)
+	if degree < 1 {
+		return nil, nil
+	}
+	knots := e.numbers(40)
+	if !validKnots(knots, n, degree) {
+		knots = clampedKnots(n, degree)
+	}
+	weights := e.numbers(41)
+	if e.flags()&4 == 0 || len(weights) != n {
+		weights = nil
+	}
+
+	var points []svg.Point
+	for span := degree; span < n; span++ {
+		from, to := knots[span], knots[span+1]
+		if to <= from {
+			continue
+		}
+		for i := 0; i < splineSamples; i++ {
+			points = append(points, deBoor(controls, weights, knots, degree, span, from+(to-from)*float64(i)/splineSamples))
+		}
+	}
+	if len(points) == 0 {
+		return nil, nil
+	}
+	return append(points, deBoor(controls, weights, knots, degree, n-1, knots[n])), nil
+}
+
+// validKnots reports whether the knots are the non decreasing knots of a spline of n control points.
+func validKnots(knots []float64, n, degree int) bool {
+	if len(knots) != n+degree+1 {
+		return false
+	}
+	for i := 1; i < len(knots); i++ {
+		if knots[i] < knots[i-1] {
+			return false
+		}
+	}
+	return knots[degree] < knots[n]
+}
+
+// clampedKnots returns the uniform knots of a spline of n control points, starting and ending on them.
+func clampedKnots(n, degree int) []float64 {
+	knots := make([]float64, n+degree+1)
+	for i := range knots {
+		knots[i] = float64(min(max(i-degree, 0), n-degree))
+	}
+	return knots
+}
+
+// deBoor returns the point at t of the spline, t being in the span of knots starting at knots[span].
+// The spline is rational with weights, unless they are nil.
+func deBoor(controls []svg.Point, weights, knots []float64, degree, span int, t float64) svg.Point {
+	type homogeneous struct{ x, y, w float64 }
+	d := make([]homogeneous, degree+1)
+	for j := range d {
+		c, w := controls[j+span-degree], 1.0
+		if weights != nil {
+			w = weights[j+span-degree]
+		}
+		d[j] = homogeneous{x: c.X * w, y: c.Y * w, w: w}
+	}
+	for r := 1; r <= degree; r++ {
+		for j := degree; j >= r; j-- {
+			var alpha float64
+			if den := knots[j+1+span-r] - knots[j+span-degree]; den != 0 {
+				alpha = (t - knots[j+span-degree]) / den
+			}
+			d[j] = homogeneous{
+				x: (1-alpha)*d[j-1].x + alpha*d[j].x,
+				y: (1-alpha)*d[j-1].y + alpha*d[j].y,
+				w: (1-alpha)*d[j-1].w + alpha*d[j].w,
+			}
+		}
+	}
+	return svg.Point{X: d[degree].x / d[degree].w, Y: d[degree].y / d[degree].w}
+}
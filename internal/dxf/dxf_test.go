@@ -0,0 +1,274 @@
+package dxf
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drawing returns an ascii DXF file with the header and the entities. Each entity is written as its
+// type, its layer and its group codes and values, separated by spaces.
+func drawing(header string, entities ...string) string {
+	var b strings.Builder
+	b.WriteString("0\nSECTION\n2\nHEADER\n")
+	for _, field := range strings.Fields(header) {
+		b.WriteString(field + "\n")
+	}
+	b.WriteString("0\nENDSEC\n0\nSECTION\n2\nENTITIES\n")
+	for _, e := range entities {
+		fields := strings.Fields(e)
+		b.WriteString("0\n" + fields[0] + "\n8\n" + fields[1] + "\n")
+		for _, field := range fields[2:] {
+			b.WriteString(field + "\n")
+		}
+	}
+	b.WriteString("0\nENDSEC\n0\nEOF\n")
+	return b.String()
+}
+
+func Test_Parse_entities(t *testing.T) {
+	tests := map[string]struct {
+		entities   []string
+		wantLength float64
+		wantWidth  float64
+		wantHeight float64
+	}{
+		"line": {
+			entities:   []string{"LINE 6MM 10 0 20 0 11 30 21 40"},
+			wantLength: 50, wantWidth: 30, wantHeight: 40,
+		},
+		"circle": {
+			entities:   []string{"CIRCLE 6MM 10 5 20 5 40 10"},
+			wantLength: 20 * math.Pi, wantWidth: 20, wantHeight: 20,
+		},
+		"arc": {
+			entities:   []string{"ARC 6MM 10 0 20 0 40 10 50 0 51 90"},
+			wantLength: 5 * math.Pi, wantWidth: 10, wantHeight: 10,
+		},
+		"arc across the x axis": {
+			entities:   []string{"ARC 6MM 10 0 20 0 40 10 50 270 51 90"},
+			wantLength: 10 * math.Pi, wantWidth: 10, wantHeight: 20,
+		},
+		"ellipse": {
+			entities:   []string{"ELLIPSE 6MM 10 0 20 0 11 0 21 20 40 0.5"},
+			wantLength: 96.884, wantWidth: 20, wantHeight: 40,
+		},
+		"closed polyline": {
+			entities:   []string{"LWPOLYLINE 6MM 90 4 70 1 10 0 20 0 10 10 20 0 10 10 20 10 10 0 20 10"},
+			wantLength: 40, wantWidth: 10, wantHeight: 10,
+		},
+		"polyline with a bulge": {
+			entities:   []string{"LWPOLYLINE 6MM 90 3 70 0 10 0 20 0 42 1 10 20 20 0 10 20 20 30"},
+			wantLength: 10*math.Pi + 30, wantWidth: 20, wantHeight: 40,
+		},
+		"polyline with vertices": {
+			entities: []string{
+				"POLYLINE 6MM 66 1 70 1",
+				"VERTEX 6MM 10 0 20 0",
+				"VERTEX 6MM 10 3 20 0",
+				"VERTEX 6MM 10 3 20 4",
+				"SEQEND 6MM",
+			},
+			wantLength: 12, wantWidth: 3, wantHeight: 4,
+		},
+		"spline of degree 1 without knots": {
+			entities:   []string{"SPLINE 6MM 71 1 73 3 10 0 20 0 10 10 20 0 10 10 20 10"},
+			wantLength: 20, wantWidth: 10, wantHeight: 10,
+		},
+		"rational spline": {
+			entities: []string{"SPLINE 6MM 70 4 71 2 72 6 73 3 40 0 40 0 40 0 40 1 40 1 40 1 " +
+				"41 1 41 0.7071067811865476 41 1 10 10 20 0 10 10 20 10 10 0 20 10"},
+			wantLength: 5 * math.Pi, wantWidth: 10, wantHeight: 10,
+		},
+		"spline through fit points": {
+			entities:   []string{"SPLINE 6MM 71 3 74 3 11 0 21 0 11 30 21 40 11 30 21 50"},
+			wantLength: 60, wantWidth: 30, wantHeight: 50,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			forms, err := RetrieveForms(strings.NewReader(drawing("", tt.entities...)), "", 1000)
+			require.NoError(t, err)
+			require.Len(t, forms["6MM"], 1)
+			form := forms["6MM"][0]
+			length, err := form.Length()
+			require.NoError(t, err)
+			assert.InDelta(t, tt.wantLength, length, tt.wantLength*0.002)
+			bounds, err := form.Bounds()
+			require.NoError(t, err)
+			assert.InDelta(t, tt.wantWidth, bounds.Width(), 0.01)
+			assert.InDelta(t, tt.wantHeight, bounds.Height(), 0.01)
+		})
+	}
+}
+
+func Test_Parse_orientation(t *testing.T) {
+	tests := map[string]struct {
+		entity string
+		// want is the middle point of the stroke, in svg coordinates.
+		want svg.Point
+	}{
+		"counterclockwise arc": {
+			entity: "ARC 6MM 10 0 20 0 40 10 50 0 51 90",
+			want:   svg.Point{X: 10 * math.Sqrt2 / 2, Y: -10 * math.Sqrt2 / 2},
+		},
+		"arc seen from below": {
+			entity: "ARC 6MM 10 0 20 0 40 10 50 0 51 90 210 0 220 0 230 -1",
+			want:   svg.Point{X: -10 * math.Sqrt2 / 2, Y: -10 * math.Sqrt2 / 2},
+		},
+		"counterclockwise bulge": {
+			entity: "LWPOLYLINE 6MM 90 2 10 0 20 0 42 1 10 20 20 0",
+			want:   svg.Point{X: 10, Y: 10},
+		},
+		"clockwise bulge": {
+			entity: "LWPOLYLINE 6MM 90 2 10 0 20 0 42 -1 10 20 20 0",
+			want:   svg.Point{X: 10, Y: -10},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			forms, err := RetrieveForms(strings.NewReader(drawing("", tt.entity)), "", 1000)
+			require.NoError(t, err)
+			require.Len(t, forms["6MM"], 1)
+			polylines, err := forms["6MM"][0].Polylines()
+			require.NoError(t, err)
+			require.Len(t, polylines, 1)
+			pl := polylines[0]
+			middle := pl[len(pl)/2]
+			assert.InDelta(t, tt.want.X, middle.X, 0.01)
+			assert.InDelta(t, tt.want.Y, middle.Y, 0.01)
+		})
+	}
+}
+
+func Test_Parse(t *testing.T) {
+	source := drawing("9 $INSUNITS 70 5",
+		"LINE 6MM 10 0 20 0 11 1 21 0",
+		"LINE 6MM 10 0 20 0 11 0 21 2",
+		"CIRCLE DECOUPE 10 0 20 0 40 5",
+		"TEXT 6MM 5 2A 1 hello",
+		"INSERT DECOUPE 5 2B 2 LOGO",
+	)
+	tests := map[string]struct {
+		layer       string
+		limits      svg.Limits
+		wantLengths map[string]float64
+		wantSkipped []svg.Skipped
+		wantErr     error
+	}{
+		"all layers": {
+			wantLengths: map[string]float64{"6MM": 30, "DECOUPE": 100 * math.Pi},
+			wantSkipped: []svg.Skipped{
+				{Group: "6MM", Element: "TEXT", ID: "2A", Reason: "unsupported entity"},
+				{Group: "DECOUPE", Element: "INSERT", ID: "2B", Reason: "block references are not measured"},
+			},
+		},
+		"one layer": {
+			layer:       "DECOUPE",
+			wantLengths: map[string]float64{"DECOUPE": 100 * math.Pi},
+			wantSkipped: []svg.Skipped{
+				{Group: "DECOUPE", Element: "INSERT", ID: "2B", Reason: "block references are not measured"},
+			},
+		},
+		"too many entities": {
+			limits:  svg.Limits{MaxElements: 4},
+			wantErr: svg.ErrLimitExceeded,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(source), tt.layer, 1000, tt.limits)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			lengths := make(map[string]float64)
+			for id, forms := range got.Forms {
+				for _, f := range forms {
+					l, err := f.Length()
+					require.NoError(t, err)
+					lengths[id] += l
+				}
+			}
+			require.Len(t, lengths, len(tt.wantLengths))
+			for id, want := range tt.wantLengths {
+				assert.InDelta(t, want, lengths[id], 1e-9, id)
+			}
+			assert.Equal(t, tt.wantSkipped, got.Skipped)
+		})
+	}
+}
+
+func Test_Parse_errors(t *testing.T) {
+	tests := map[string]struct {
+		source  string
+		limits  svg.Limits
+		wantErr error
+	}{
+		"binary": {
+			source:  "AutoCAD Binary DXF\r\n\x1a\x00",
+			wantErr: ErrBinary,
+		},
+		"invalid group code": {
+			source: "0\nSECTION\nx\nENTITIES\n",
+		},
+		"invalid number": {
+			source: drawing("", "LINE 6MM 10 zero"),
+		},
+		"not a number": {
+			source: drawing("", "LWPOLYLINE 6MM 90 2 10 0 20 0 42 NaN 10 10 20 0"),
+		},
+		"infinite number": {
+			source: drawing("", "LINE 6MM 10 0 20 0 11 Inf 21 0"),
+		},
+		"number out of range": {
+			source: drawing("", "LINE 6MM 10 0 20 0 11 1e400 21 0"),
+		},
+		"circle without radius": {
+			source: drawing("", "CIRCLE 6MM 10 0 20 0"),
+		},
+		"unsupported units": {
+			source: drawing("9 $INSUNITS 70 3"),
+		},
+		"too many path commands": {
+			source:  drawing("", "ARC 6MM 10 0 20 0 40 10 50 0 51 270"),
+			limits:  svg.Limits{MaxPathCommands: 3},
+			wantErr: svg.ErrLimitExceeded,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.source), "", 1000, tt.limits)
+			require.Error(t, err)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_IsDXF(t *testing.T) {
+	tests := map[string]struct {
+		data string
+		want bool
+	}{
+		"section":         {data: "  0\r\nSECTION\r\n  2\r\nHEADER\r\n", want: true},
+		"comment":         {data: "999\ndxfrw 0.6.3\n0\nSECTION\n", want: true},
+		"with a bom":      {data: "\xef\xbb\xbf0\nSECTION\n", want: true},
+		"binary":          {data: "AutoCAD Binary DXF\r\n\x1a\x00", want: true},
+		"svg":             {data: `<svg><g id="6MM"></g></svg>`},
+		"svg with a decl": {data: "<?xml version=\"1.0\"?>\n<svg/>"},
+		"empty":           {},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsDXF([]byte(tt.data)))
+		})
+	}
+}
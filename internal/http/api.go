@@ -1,48 +1,111 @@
 package http
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"html/template"
+	"io"
 	"math"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"theo303/neon-pricer/conf"
-	"theo303/neon-pricer/internal/svg"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/storage"
 	"theo303/neon-pricer/internal/usecases"
 
 	"github.com/gin-gonic/gin"
 )
 
 type API struct {
-	config *conf.Configuration
-	port   int
+	config *conf.Store
+	// quotes stores the computed quotes, they are not kept if nil.
+	quotes *storage.QuoteStore
+	server ServerConfig
+	// ready is set once the templates are loaded and until shutdown starts.
+	ready *atomic.Bool
+	// computations holds a token for each running computation, it is nil without limit.
+	computations chan struct{}
 
 	configHandlers configHandlers
+	quoteHandlers  quoteHandlers
 }
 
-func NewAPI(config conf.Configuration, port int) API {
+func NewAPI(config *conf.Store, quotes *storage.QuoteStore, server ServerConfig) API {
+	var computations chan struct{}
+	if server.MaxComputations > 0 {
+		computations = make(chan struct{}, server.MaxComputations)
+	}
 	return API{
-		config: &config,
-		port:   port,
+		config:       config,
+		quotes:       quotes,
+		server:       server,
+		ready:        &atomic.Bool{},
+		computations: computations,
 		configHandlers: configHandlers{
-			config: &config,
+			config: config,
+		},
+		quoteHandlers: quoteHandlers{
+			config: config,
+			quotes: quotes,
 		},
 	}
 }
 
-func (a API) Run() error {
+func (a API) router() (*gin.Engine, error) {
 	r := gin.Default()
 
-	r.LoadHTMLGlob("templates/*")
+	tmpl, err := template.New("").Funcs(template.FuncMap{"pathEscape": url.PathEscape}).ParseFS(a.server.Templates, "*.html")
+	if err != nil {
+		return nil, fmt.Errorf("parsing templates: %w", err)
+	}
+	r.SetHTMLTemplate(tmpl)
+
+	r.GET("/healthz", a.healthz())
+	r.GET("/readyz", a.readyz())
 
 	r.GET("/", func(c *gin.Context) {
 		c.HTML(http.StatusOK, "index.html", nil)
 	})
 	r.GET("/config", a.configHandlers.getConfig())
 	r.POST("/config", a.configHandlers.setConfig())
+	r.POST("/config/:catalogue", a.configHandlers.addItem())
+	r.PATCH("/config/:catalogue/:name", a.configHandlers.renameItem())
+	r.DELETE("/config/:catalogue/:name", a.configHandlers.removeItem())
 	r.GET("/input", a.configHandlers.getInput())
 	r.POST("/compute", a.compute())
 
-	return r.Run(fmt.Sprintf(":%d", a.port))
+	api := r.Group("/api")
+	api.GET("/config", a.configHandlers.apiGetConfig())
+	api.POST("/config/:catalogue", a.configHandlers.apiAddItem())
+	api.PATCH("/config/:catalogue/:name", a.configHandlers.apiRenameItem())
+	api.DELETE("/config/:catalogue/:name", a.configHandlers.apiRemoveItem())
+	api.POST("/diff", a.apiDiff())
+	api.POST("/check", a.apiCheck())
+
+	if a.quotes != nil {
+		r.GET("/quotes", a.quoteHandlers.listQuotes())
+		r.GET("/quotes/:id", a.quoteHandlers.getQuote())
+		r.GET("/quotes/:id/svg", a.quoteHandlers.getQuoteSVG())
+		r.GET("/quotes/:id/pdf", a.quoteHandlers.getQuotePDF())
+		r.GET("/quotes/:id/preview.png", a.quoteHandlers.getQuotePreview())
+		r.GET("/quotes/:id/annotated.svg", a.quoteHandlers.getQuoteAnnotations())
+		r.GET("/quotes/:id/wiring.svg", a.quoteHandlers.getQuoteWiring())
+		r.GET("/quotes/:id/cut.svg", a.quoteHandlers.getQuoteCutFile(usecases.CutFormatSVG))
+		r.GET("/quotes/:id/cut.dxf", a.quoteHandlers.getQuoteCutFile(usecases.CutFormatDXF))
+		api.GET("/quotes", a.quoteHandlers.apiListQuotes())
+		api.GET("/quotes/:id", a.quoteHandlers.apiGetQuote())
+		api.POST("/quotes/reprice", a.quoteHandlers.apiReprice())
+	}
+
+	return r, nil
 }
 
 type computationResult struct {
@@ -54,57 +117,353 @@ type computationResult struct {
 	SiliconePrice float64
 	LedPrice      float64
 	PlexiPrice    float64
+	Corners       int
+	LabourPrice   float64
+	FeedPoints    int
+	// WiringPrice is the price of the feed points, of the jumpers between them and of their cables.
+	WiringPrice float64
 }
 type resultData struct {
-	Results []computationResult
+	// Preview is the url of the preview image, inlined as data url when the quote is not saved.
+	Preview   template.URL
+	QuoteID   string
+	FileName  string
+	CreatedAt string
+	Plexi     string
+	Quantity  int
+	// SaveError tells the quote was computed but could not be saved.
+	SaveError string
+	// Annotated is set when the measures can be drawn over the design, a svg file.
+	Annotated bool
+	// UnitTotal is the price of one sign.
+	UnitTotal float64
+	Total     float64
+	Results   []computationResult
+}
+
+func newResultData(quote domain.Quote) resultData {
+	resData := resultData{
+		QuoteID:   quote.ID,
+		FileName:  quote.FileName,
+		CreatedAt: quote.CreatedAt.Format("2006-01-02 15:04"),
+		Plexi:     quote.Plexi,
+		Quantity:  quote.Copies(),
+		Annotated: usecases.DesignFormat(quote.FileName, quote.SVG) == usecases.DesignSVG,
+		UnitTotal: quote.Prices.Total(),
+		Total:     quote.Total,
+	}
+	groups := make([]string, 0, len(quote.Sizes))
+	for g := range quote.Sizes {
+		groups = append(groups, g)
+	}
+	slices.Sort(groups)
+	for _, g := range groups {
+		size := quote.Sizes[g]
+		price := quote.Prices[strings.ToUpper(g)]
+		resData.Results = append(resData.Results, computationResult{
+			Group:         g,
+			LengthPx:      math.Round(size.LengthPx),
+			LengthMm:      math.Round(size.Length),
+			WidthMm:       math.Round(size.Width),
+			HeightMm:      math.Round(size.Height),
+			SiliconePrice: price.SiliconePrice,
+			LedPrice:      price.LEDPrice,
+			PlexiPrice:    price.PlexiPrice,
+			Corners:       size.Corners,
+			LabourPrice:   price.LabourPrice,
+			FeedPoints:    size.FeedPoints,
+			WiringPrice:   domain.Round(price.FeedPrice + price.JumperPrice + price.CablePrice),
+		})
+	}
+	return resData
+}
+
+type computation struct {
+	quote   domain.Quote
+	preview []byte
+	err     error
 }
 
 func (a API) compute() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		file, err := c.FormFile("file")
+		if a.server.MaxUploadBytes > 0 {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, a.server.MaxUploadBytes)
+		}
+		fileName, raw, status, err := formFile(c, "file")
 		if err != nil {
-			_ = c.AbortWithError(http.StatusBadRequest, err)
+			abortWithMessage(c, status, err)
 			return
 		}
 
-		fileBuf, err := file.Open()
+		quantity, err := formQuantity(c)
 		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithMessage(c, http.StatusBadRequest, err)
 			return
 		}
 
-		forms, err := svg.RetrieveForms(fileBuf, "")
+		config := a.config.Get()
+		if err := formBacking(c, &config); err != nil {
+			abortWithMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		plexi := c.PostForm("plexi")
+		var res computation
+		err = a.withinComputeTimeout(c.Request.Context(), func() {
+			res.quote, res.err = usecases.NewQuote(fileName, raw, config, plexi, quantity, a.server.SVGLimits)
+			if res.err != nil {
+				return
+			}
+			var preview bytes.Buffer
+			if err := usecases.WritePreview(&preview, res.quote, usecases.PreviewOptions{}); err != nil {
+				fmt.Printf("compute: error while rendering preview: %s\n", err)
+			}
+			res.preview = preview.Bytes()
+		})
 		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithMessage(c, computeErrorStatus(err), err)
 			return
 		}
+		if errors.Is(res.err, usecases.ErrInvalidDesign) {
+			abortWithMessage(c, http.StatusUnprocessableEntity, res.err)
+			return
+		}
+		if res.err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, res.err)
+			return
+		}
+
+		var saveErr error
+		if a.quotes != nil {
+			if saveErr = a.quotes.Save(&res.quote); saveErr != nil {
+				fmt.Printf("compute: error while saving quote: %s\n", saveErr)
+				// the id is set before the quote is written, it does not designate a saved quote.
+				res.quote.ID = ""
+			}
+		}
 
-		sizes, err := usecases.GetSizes(forms, a.config.Scale)
+		resData := newResultData(res.quote)
+		if saveErr != nil {
+			resData.SaveError = "the quote could not be saved, it is only shown on this page"
+		}
+		if len(res.preview) > 0 {
+			resData.Preview = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(res.preview))
+		}
+		c.HTML(http.StatusOK, "response.html", resData)
+	}
+}
+
+// apiDiff compares the designs uploaded as before and after, quoted with the optional plexi and quantity.
+func (a API) apiDiff() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.server.MaxUploadBytes > 0 {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, a.server.MaxUploadBytes)
+		}
+		_, before, status, err := formFile(c, "before")
+		if err != nil {
+			abortWithJSONError(c, status, err)
+			return
+		}
+		_, after, status, err := formFile(c, "after")
 		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithJSONError(c, status, err)
 			return
 		}
-		fmt.Println(c.PostForm("plexi"))
-		prices, err := usecases.GetPrice(a.config.Pricing, sizes, c.PostForm("plexi"))
+		quantity, err := formQuantity(c)
 		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			abortWithJSONError(c, http.StatusBadRequest, err)
 			return
 		}
 
-		var resData resultData
-		for g, size := range sizes {
-			resData.Results = append(resData.Results, computationResult{
-				Group:         g,
-				LengthPx:      math.Round(size.LengthPx),
-				LengthMm:      math.Round(size.Length),
-				WidthMm:       math.Round(size.Width),
-				HeightMm:      math.Round(size.Height),
-				SiliconePrice: prices[g].SiliconePrice,
-				LedPrice:      prices[g].LEDPrice,
-				PlexiPrice:    prices[g].PlexiPrice,
-			})
+		config := a.config.Get()
+		if err := formBacking(c, &config); err != nil {
+			abortWithJSONError(c, http.StatusBadRequest, err)
+			return
 		}
+		plexi := c.PostForm("plexi")
+		var delta usecases.QuoteDelta
+		var diffErr error
+		err = a.withinComputeTimeout(c.Request.Context(), func() {
+			delta, diffErr = usecases.DiffDesigns(before, after, config, plexi, quantity, a.server.SVGLimits)
+		})
+		if err != nil {
+			abortWithJSONError(c, computeErrorStatus(err), err)
+			return
+		}
+		if errors.Is(diffErr, usecases.ErrInvalidDesign) {
+			abortWithJSONError(c, http.StatusUnprocessableEntity, diffErr)
+			return
+		}
+		if diffErr != nil {
+			abortWithJSONError(c, http.StatusInternalServerError, diffErr)
+			return
+		}
+		c.JSON(http.StatusOK, delta)
+	}
+}
 
-		c.HTML(http.StatusOK, "response.html", resData)
+// apiCheck checks that the design uploaded as file can be built with the optional plexi.
+func (a API) apiCheck() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.server.MaxUploadBytes > 0 {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, a.server.MaxUploadBytes)
+		}
+		fileName, raw, status, err := formFile(c, "file")
+		if err != nil {
+			abortWithJSONError(c, status, err)
+			return
+		}
+
+		config := a.config.Get()
+		if err := formBacking(c, &config); err != nil {
+			abortWithJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+		plexi := c.PostForm("plexi")
+		var issues []domain.Issue
+		var checkErr error
+		err = a.withinComputeTimeout(c.Request.Context(), func() {
+			issues, checkErr = usecases.CheckDesign(fileName, raw, config, plexi, a.server.SVGLimits)
+		})
+		if err != nil {
+			abortWithJSONError(c, computeErrorStatus(err), err)
+			return
+		}
+		if errors.Is(checkErr, usecases.ErrInvalidDesign) {
+			abortWithJSONError(c, http.StatusUnprocessableEntity, checkErr)
+			return
+		}
+		if checkErr != nil {
+			abortWithJSONError(c, http.StatusInternalServerError, checkErr)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"issues": issues})
+	}
+}
+
+// formFile reads the file uploaded as field, returning the status to answer with if it fails.
+func formFile(c *gin.Context, field string) (string, []byte, int, error) {
+	file, err := c.FormFile(field)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return "", nil, http.StatusRequestEntityTooLarge,
+				fmt.Errorf("uploaded file exceeds the limit of %d bytes", maxBytesErr.Limit)
+		}
+		return "", nil, http.StatusBadRequest, fmt.Errorf("%s: %w", field, err)
+	}
+	f, err := file.Open()
+	if err != nil {
+		return "", nil, http.StatusInternalServerError, err
+	}
+	defer f.Close()
+	raw, err := io.ReadAll(f)
+	if err != nil {
+		return "", nil, http.StatusInternalServerError, err
+	}
+	return file.Filename, raw, http.StatusOK, nil
+}
+
+// formQuantity returns the number of signs of the quantity field, 1 if empty.
+func formQuantity(c *gin.Context) (int, error) {
+	q := c.PostForm("quantity")
+	if q == "" {
+		return 1, nil
 	}
+	quantity, err := strconv.Atoi(q)
+	if err != nil || quantity < 1 {
+		return 0, fmt.Errorf("quantity must be a positive integer, got %s", q)
+	}
+	return quantity, nil
+}
+
+// formBacking sets the backing mode of the configuration to the one of the backing field, if set.
+func formBacking(c *gin.Context, config *conf.Configuration) error {
+	mode := c.PostForm("backing")
+	if mode == "" {
+		return nil
+	}
+	if !slices.Contains(conf.BackingModes, mode) {
+		return fmt.Errorf("unknown backing mode %q, expected one of %s", mode, strings.Join(conf.BackingModes, ", "))
+	}
+	config.Backing.Mode = mode
+	return nil
+}
+
+// errBusy is returned when no computation could start within the compute timeout.
+var errBusy = errors.New("server busy: too many designs being priced, retry later")
+
+// errCanceled is returned when the client cancels its request before the computation is done.
+var errCanceled = errors.New("request canceled by the client")
+
+// errComputeFailed is returned when the computation panics.
+var errComputeFailed = errors.New("computation failed")
+
+// statusClientClosedRequest is the non standard status logged for the requests canceled by their client.
+const statusClientClosedRequest = 499
+
+// withinComputeTimeout runs compute, failing if it exceeds the compute timeout or if ctx is canceled.
+// The computation is bounded by the svg limits, so it is left to finish
+// in the background if it exceeds the time budget, holding its slot among the
+// computations running at once until then. Waiting for a slot counts in the budget.
+func (a API) withinComputeTimeout(ctx context.Context, compute func()) error {
+	if a.server.ComputeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.server.ComputeTimeout)
+		defer cancel()
+	}
+	if a.computations != nil {
+		select {
+		case a.computations <- struct{}{}:
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return errCanceled
+			}
+			return errBusy
+		}
+	}
+	done := make(chan struct{})
+	// failure is set when compute panics, the panic not being recovered by the router outside of the request goroutine.
+	var failure error
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				failure = fmt.Errorf("%w: %v", errComputeFailed, r)
+			}
+			if a.computations != nil {
+				<-a.computations
+			}
+			close(done)
+		}()
+		compute()
+	}()
+	select {
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return errCanceled
+		}
+		return fmt.Errorf("design too complex: computation exceeded %s", a.server.ComputeTimeout)
+	case <-done:
+		return failure
+	}
+}
+
+// computeErrorStatus returns the status to answer with when withinComputeTimeout fails with err.
+func computeErrorStatus(err error) int {
+	if errors.Is(err, errCanceled) {
+		return statusClientClosedRequest
+	}
+	if errors.Is(err, errBusy) {
+		return http.StatusServiceUnavailable
+	}
+	if errors.Is(err, errComputeFailed) {
+		return http.StatusInternalServerError
+	}
+	return http.StatusUnprocessableEntity
+}
+
+// abortWithMessage aborts the request with the error message as plain text body.
+func abortWithMessage(c *gin.Context, status int, err error) {
+	_ = c.Error(err)
+	c.Abort()
+	c.String(status, err.Error())
 }
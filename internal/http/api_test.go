@@ -0,0 +1,137 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newResultData(t *testing.T) {
+	quote := domain.Quote{
+		FileName: "sign.svg",
+		Sizes:    map[string]domain.Size{"8mm": {Length: 1000, Corners: 2, FeedPoints: 1}},
+		Prices: domain.Price{"8MM": {
+			SiliconePrice: 10,
+			LEDPrice:      5,
+			PlexiPrice:    3,
+			LabourPrice:   2,
+			FeedPrice:     1,
+			JumperPrice:   0.5,
+			CablePrice:    0.25,
+		}},
+	}
+
+	got := newResultData(quote)
+	require.Len(t, got.Results, 1)
+	assert.Equal(t, computationResult{
+		Group:         "8mm",
+		LengthMm:      1000,
+		SiliconePrice: 10,
+		LedPrice:      5,
+		PlexiPrice:    3,
+		Corners:       2,
+		LabourPrice:   2,
+		FeedPoints:    1,
+		WiringPrice:   1.75,
+	}, got.Results[0])
+}
+
+// newTestAPI returns an api serving the templates of the repository with the server configuration.
+func newTestAPI(t *testing.T, server ServerConfig) (API, *gin.Engine) {
+	gin.SetMode(gin.TestMode)
+	server.Templates = os.DirFS("../../templates")
+	config := conf.Configuration{
+		Scale:   1000,
+		Pricing: conf.Pricing{Silicones: []conf.Silicone{{SizeMm: 6, PricePerMeter: 1}}},
+	}
+	a := NewAPI(conf.NewStore(config), nil, server)
+	r, err := a.router()
+	require.NoError(t, err)
+	return a, r
+}
+
+// uploadRequest returns a request uploading design as the file field of a multipart form.
+func uploadRequest(t *testing.T, target, design string) *http.Request {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "design.svg")
+	require.NoError(t, err)
+	_, err = part.Write([]byte(design))
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func Test_apiCheck_status(t *testing.T) {
+	const design = `<svg><g id="6MM"><line x1="0" y1="0" x2="100" y2="0"/></g></svg>`
+	tests := map[string]struct {
+		server     ServerConfig
+		design     string
+		saturated  bool
+		wantStatus int
+	}{
+		"valid": {
+			design:     design,
+			wantStatus: http.StatusOK,
+		},
+		"oversize body": {
+			server:     ServerConfig{MaxUploadBytes: 100},
+			design:     design + strings.Repeat(" ", 200),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		"invalid design": {
+			design:     `<svg><g id="6MM"><line x1="0"`,
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		"failing computation": {
+			design:     `<svg><g id="6MM"><path d="M0,0 L"/></g></svg>`,
+			wantStatus: http.StatusInternalServerError,
+		},
+		"saturated workers": {
+			server:     ServerConfig{MaxComputations: 1, ComputeTimeout: 10 * time.Millisecond},
+			design:     design,
+			saturated:  true,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			a, r := newTestAPI(t, tt.server)
+			if tt.saturated {
+				a.computations <- struct{}{}
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, uploadRequest(t, "/api/check", tt.design))
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+		})
+	}
+}
+
+func Test_withinComputeTimeout_canceled(t *testing.T) {
+	a, _ := newTestAPI(t, ServerConfig{MaxComputations: 1, ComputeTimeout: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := a.withinComputeTimeout(ctx, func() { time.Sleep(10 * time.Millisecond) })
+	assert.ErrorIs(t, err, errCanceled)
+	assert.Equal(t, statusClientClosedRequest, computeErrorStatus(err))
+
+	a.computations <- struct{}{}
+	err = a.withinComputeTimeout(ctx, func() {})
+	assert.ErrorIs(t, err, errCanceled, "waiting for a slot")
+}
@@ -1,9 +1,11 @@
 package http
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
 	"theo303/neon-pricer/conf"
 	"theo303/neon-pricer/internal/usecases"
@@ -12,12 +14,12 @@ import (
 )
 
 type configHandlers struct {
-	config *conf.Configuration
+	config *conf.Store
 }
 
 func (ch configHandlers) getConfig() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.HTML(http.StatusOK, "config.html", ch.config)
+		ch.renderConfig(c, nil)
 	}
 }
 
@@ -30,7 +32,10 @@ func (ch configHandlers) setConfig() gin.HandlerFunc {
 			return
 		}
 
-		ch.config, err = usecases.UpdateConfigWithPostForm(ch.config, body)
+		err = ch.config.Update(func(config *conf.Configuration) error {
+			_, err := usecases.UpdateConfigWithPostForm(config, body)
+			return err
+		})
 		if err != nil {
 			fmt.Printf("setConfig: error while updating config with body %s: %s", string(body), err)
 			c.Status(http.StatusBadRequest)
@@ -48,15 +53,147 @@ type radioButton struct {
 
 func (ch configHandlers) getInput() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		config := ch.config.Get()
 		data := struct {
-			Plexis []radioButton
+			Plexis   []radioButton
+			Backings []radioButton
 		}{}
-		for _, plexi := range ch.config.Plexis {
+		for _, plexi := range config.Plexis {
 			data.Plexis = append(data.Plexis, radioButton{
 				Name:      plexi.Name,
 				IsDefault: plexi.Name == "incolore",
 			})
 		}
+		for _, mode := range conf.BackingModes {
+			data.Backings = append(data.Backings, radioButton{
+				Name:      mode,
+				IsDefault: mode == config.Backing.Mode || (mode == conf.BackingNone && config.Backing.Mode == ""),
+			})
+		}
 		c.HTML(http.StatusOK, "input.html", data)
 	}
 }
+
+type configData struct {
+	*conf.Configuration
+	Error string
+}
+
+// renderConfig renders the configuration page, with err displayed on top if not nil.
+// The page is always rendered with a 200 status so htmx swaps it in.
+func (ch configHandlers) renderConfig(c *gin.Context, err error) {
+	config := ch.config.Get()
+	data := configData{Configuration: &config}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	c.HTML(http.StatusOK, "config.html", data)
+}
+
+func (ch configHandlers) addItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		price, err := strconv.ParseFloat(c.PostForm("price"), 64)
+		if err != nil {
+			ch.renderConfig(c, fmt.Errorf("parsing price %s: %w", c.PostForm("price"), err))
+			return
+		}
+		err = ch.config.Update(func(config *conf.Configuration) error {
+			return usecases.AddCatalogueItem(config, c.Param("catalogue"), c.PostForm("name"), price)
+		})
+		ch.renderConfig(c, err)
+	}
+}
+
+func (ch configHandlers) renameItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := ch.config.Update(func(config *conf.Configuration) error {
+			return usecases.RenameCatalogueItem(config, c.Param("catalogue"), c.Param("name"), c.PostForm("name"))
+		})
+		ch.renderConfig(c, err)
+	}
+}
+
+func (ch configHandlers) removeItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := ch.config.Update(func(config *conf.Configuration) error {
+			return usecases.RemoveCatalogueItem(config, c.Param("catalogue"), c.Param("name"))
+		})
+		ch.renderConfig(c, err)
+	}
+}
+
+type catalogueItem struct {
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+func (ch configHandlers) apiGetConfig() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, ch.config.Get())
+	}
+}
+
+func (ch configHandlers) apiAddItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var item catalogueItem
+		if err := c.ShouldBindJSON(&item); err != nil {
+			abortWithJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+		err := ch.config.Update(func(config *conf.Configuration) error {
+			return usecases.AddCatalogueItem(config, c.Param("catalogue"), item.Name, item.Price)
+		})
+		if err != nil {
+			abortWithJSONError(c, catalogueErrorStatus(err), err)
+			return
+		}
+		c.JSON(http.StatusCreated, ch.config.Get())
+	}
+}
+
+func (ch configHandlers) apiRenameItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var item catalogueItem
+		if err := c.ShouldBindJSON(&item); err != nil {
+			abortWithJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+		err := ch.config.Update(func(config *conf.Configuration) error {
+			return usecases.RenameCatalogueItem(config, c.Param("catalogue"), c.Param("name"), item.Name)
+		})
+		if err != nil {
+			abortWithJSONError(c, catalogueErrorStatus(err), err)
+			return
+		}
+		c.JSON(http.StatusOK, ch.config.Get())
+	}
+}
+
+func (ch configHandlers) apiRemoveItem() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := ch.config.Update(func(config *conf.Configuration) error {
+			return usecases.RemoveCatalogueItem(config, c.Param("catalogue"), c.Param("name"))
+		})
+		if err != nil {
+			abortWithJSONError(c, catalogueErrorStatus(err), err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func catalogueErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrUnknownCatalogue), errors.Is(err, usecases.ErrItemNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrDuplicateItem):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func abortWithJSONError(c *gin.Context, status int, err error) {
+	_ = c.Error(err)
+	c.AbortWithStatusJSON(status, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ledDelete matches the urls deleting the LEDs on the configuration page.
+var ledDelete = regexp.MustCompile(`hx-delete="(/config/led/[^"]*)"`)
+
+func Test_configHandlers_itemRoundTrip(t *testing.T) {
+	const name = "50% off? #1"
+	a, r := newTestAPI(t, ServerConfig{})
+	form := func(method, target string, values url.Values) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, form(http.MethodPost, "/config/led", url.Values{"name": {name}, "price": {"10"}}))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, a.config.Get().LEDs, 1)
+
+	// the item is renamed and deleted through the urls of the configuration page.
+	match := ledDelete.FindStringSubmatch(w.Body.String())
+	require.NotNil(t, match)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, form(http.MethodPatch, html.UnescapeString(match[1]), url.Values{"name": {name + "?"}}))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, name+"?", a.config.Get().LEDs[0].Name, w.Body.String())
+
+	match = ledDelete.FindStringSubmatch(w.Body.String())
+	require.NotNil(t, match)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, form(http.MethodDelete, html.UnescapeString(match[1]), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, a.config.Get().LEDs, w.Body.String())
+}
@@ -0,0 +1,315 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/storage"
+	"theo303/neon-pricer/internal/usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+const dateLayout = "2006-01-02"
+
+type quoteHandlers struct {
+	config *conf.Store
+	quotes *storage.QuoteStore
+}
+
+// filterFromQuery builds a quote filter from the q, hash, since, until and limit query parameters,
+// dates are formatted as 2006-01-02 and until is inclusive.
+func filterFromQuery(c *gin.Context) (storage.Filter, error) {
+	filter := storage.Filter{
+		Search: c.Query("q"),
+		Hash:   c.Query("hash"),
+	}
+	var err error
+	if since := c.Query("since"); since != "" {
+		if filter.Since, err = time.ParseInLocation(dateLayout, since, time.Local); err != nil {
+			return storage.Filter{}, fmt.Errorf("parsing since: %w", err)
+		}
+	}
+	if until := c.Query("until"); until != "" {
+		if filter.Until, err = time.ParseInLocation(dateLayout, until, time.Local); err != nil {
+			return storage.Filter{}, fmt.Errorf("parsing until: %w", err)
+		}
+		filter.Until = filter.Until.AddDate(0, 0, 1)
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return storage.Filter{}, fmt.Errorf("parsing limit: %w", err)
+		}
+	}
+	return filter, nil
+}
+
+type quotesData struct {
+	Search string
+	Hash   string
+	Since  string
+	Until  string
+	Quotes []domain.Quote
+}
+
+func (qh quoteHandlers) listQuotes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, err := filterFromQuery(c)
+		if err != nil {
+			abortWithMessage(c, http.StatusBadRequest, err)
+			return
+		}
+		quotes, err := qh.quotes.List(filter)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.HTML(http.StatusOK, "quotes.html", quotesData{
+			Search: c.Query("q"),
+			Hash:   c.Query("hash"),
+			Since:  c.Query("since"),
+			Until:  c.Query("until"),
+			Quotes: quotes,
+		})
+	}
+}
+
+func (qh quoteHandlers) getQuote() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		quote, ok := qh.quote(c, abortWithMessage)
+		if !ok {
+			return
+		}
+		resData := newResultData(quote)
+		resData.Preview = template.URL("/quotes/" + quote.ID + "/preview.png")
+		c.HTML(http.StatusOK, "quote.html", resData)
+	}
+}
+
+// untrustedDesignPolicy forbids scripts and remote resources in the documents embedding uploaded designs.
+const untrustedDesignPolicy = "default-src 'none'; style-src 'unsafe-inline'"
+
+// sendUntrustedDesign answers with the document embedding the uploaded design as an attachment named
+// fileName, scripts it may hold being prevented from running on the origin of the application.
+func sendUntrustedDesign(c *gin.Context, fileName, contentType string, data []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	c.Header("Content-Security-Policy", untrustedDesignPolicy)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// designTypes are the content types of the design formats.
+var designTypes = map[string]string{
+	usecases.DesignSVG: "image/svg+xml",
+	usecases.DesignDXF: "image/vnd.dxf",
+}
+
+func (qh quoteHandlers) getQuoteSVG() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		quote, ok := qh.quote(c, abortWithMessage)
+		if !ok {
+			return
+		}
+		sendUntrustedDesign(c, quote.FileName, designTypes[usecases.DesignFormat(quote.FileName, quote.SVG)], quote.SVG)
+	}
+}
+
+// getQuotePDF renders the customer document of the quote, branded with the current configuration.
+func (qh quoteHandlers) getQuotePDF() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		quote, ok := qh.quote(c, abortWithMessage)
+		if !ok {
+			return
+		}
+		var buf bytes.Buffer
+		if err := usecases.WriteQuotePDF(&buf, quote, qh.config.Get().Branding); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "quote-"+quote.ID+".pdf"))
+		c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+	}
+}
+
+// getQuotePreview renders the design of the quote as a png image, on a dark background if dark is set.
+func (qh quoteHandlers) getQuotePreview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		quote, ok := qh.quote(c, abortWithMessage)
+		if !ok {
+			return
+		}
+		options := usecases.PreviewOptions{Dark: c.Query("dark") != ""}
+		if width := c.Query("width"); width != "" {
+			var err error
+			if options.Width, err = strconv.Atoi(width); err != nil || options.Width <= 0 || options.Width > 4000 {
+				abortWithMessage(c, http.StatusBadRequest, fmt.Errorf("width must be between 1 and 4000, got %s", width))
+				return
+			}
+		}
+		var buf bytes.Buffer
+		if err := usecases.WritePreview(&buf, quote, options); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.Data(http.StatusOK, "image/png", buf.Bytes())
+	}
+}
+
+// getQuoteAnnotations renders the design of the quote with its measures drawn over it, as an attachment
+// since it embeds the uploaded design.
+func (qh quoteHandlers) getQuoteAnnotations() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		quote, ok := qh.quote(c, abortWithMessage)
+		if !ok {
+			return
+		}
+		var buf bytes.Buffer
+		if _, err := usecases.WriteAnnotations(&buf, quote.FileName, quote.SVG, quote.Config); err != nil {
+			if errors.Is(err, usecases.ErrNotSVG) {
+				abortWithMessage(c, http.StatusNotFound, err)
+				return
+			}
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		sendUntrustedDesign(c, "quote-"+quote.ID+".annotated.svg", "image/svg+xml", buf.Bytes())
+	}
+}
+
+// getQuoteWiring renders the wiring diagram of the design of the quote.
+func (qh quoteHandlers) getQuoteWiring() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		quote, ok := qh.quote(c, abortWithMessage)
+		if !ok {
+			return
+		}
+		var buf bytes.Buffer
+		if _, err := usecases.WriteWiring(&buf, quote.FileName, quote.SVG, quote.Config); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.Data(http.StatusOK, "image/svg+xml", buf.Bytes())
+	}
+}
+
+// cutFileTypes are the content types of the cut file formats.
+var cutFileTypes = map[string]string{
+	usecases.CutFormatSVG: "image/svg+xml",
+	usecases.CutFormatDXF: "image/vnd.dxf",
+}
+
+// getQuoteCutFile renders the cut file of the plexi backing of the design of the quote in the format.
+func (qh quoteHandlers) getQuoteCutFile(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		quote, ok := qh.quote(c, abortWithMessage)
+		if !ok {
+			return
+		}
+		var buf bytes.Buffer
+		if _, err := usecases.WriteCutFile(&buf, quote.FileName, quote.SVG, quote.Config, format); err != nil {
+			if errors.Is(err, usecases.ErrNoBacking) {
+				abortWithMessage(c, http.StatusNotFound, err)
+				return
+			}
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "quote-"+quote.ID+".cut."+format))
+		c.Data(http.StatusOK, cutFileTypes[format], buf.Bytes())
+	}
+}
+
+func (qh quoteHandlers) apiListQuotes() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, err := filterFromQuery(c)
+		if err != nil {
+			abortWithJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+		quotes, err := qh.quotes.List(filter)
+		if err != nil {
+			abortWithJSONError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if quotes == nil {
+			quotes = []domain.Quote{}
+		}
+		c.JSON(http.StatusOK, quotes)
+	}
+}
+
+func (qh quoteHandlers) apiGetQuote() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		quote, ok := qh.quote(c, abortWithJSONError)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, quote)
+	}
+}
+
+// apiReprice prices the quotes selected by the query parameters with the configuration
+// given as body, or the current configuration if the body is empty.
+func (qh quoteHandlers) apiReprice() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, err := filterFromQuery(c)
+		if err != nil {
+			abortWithJSONError(c, http.StatusBadRequest, err)
+			return
+		}
+		config := qh.config.Get()
+		if c.Request.ContentLength != 0 {
+			config = conf.Configuration{}
+			if err := c.ShouldBindJSON(&config); err != nil {
+				abortWithJSONError(c, http.StatusBadRequest, err)
+				return
+			}
+			if err := config.Validate(); err != nil {
+				abortWithJSONError(c, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		quotes, err := qh.quotes.List(filter)
+		if err != nil {
+			abortWithJSONError(c, http.StatusInternalServerError, err)
+			return
+		}
+		// the quotes without sizes are measured again from their svg file, which is not listed.
+		for i, q := range quotes {
+			if len(q.Sizes) == 0 {
+				if quotes[i], err = qh.quotes.Get(q.ID); err != nil {
+					abortWithJSONError(c, http.StatusInternalServerError, err)
+					return
+				}
+			}
+		}
+		report, err := usecases.RepriceQuotes(quotes, config)
+		if err != nil {
+			abortWithJSONError(c, http.StatusUnprocessableEntity, err)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// quote retrieves the quote identified by the id parameter, and aborts the request with abort if it fails.
+func (qh quoteHandlers) quote(c *gin.Context, abort func(*gin.Context, int, error)) (domain.Quote, bool) {
+	quote, err := qh.quotes.Get(c.Param("id"))
+	if errors.Is(err, storage.ErrQuoteNotFound) {
+		abort(c, http.StatusNotFound, err)
+		return domain.Quote{}, false
+	}
+	if err != nil {
+		abort(c, http.StatusInternalServerError, err)
+		return domain.Quote{}, false
+	}
+	return quote, true
+}
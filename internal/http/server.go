@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServerConfig defines how the server listens and where it finds its templates.
+type ServerConfig struct {
+	// Addr is the listen address, such as ":8080".
+	Addr string
+	// Templates contains the html templates at its root.
+	Templates fs.FS
+	// TLSCertFile and TLSKeyFile enable TLS when both are set.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// ShutdownTimeout is the maximum duration to wait for pending requests on shutdown.
+	ShutdownTimeout time.Duration
+
+	// MaxUploadBytes is the maximum size of a request to /compute, 0 disables the limit.
+	MaxUploadBytes int64
+	// ComputeTimeout is the time budget to parse and price a design, 0 disables the limit.
+	ComputeTimeout time.Duration
+	// MaxComputations is the maximum number of designs parsed and priced at once, 0 disables the limit.
+	// Computations exceeding the compute timeout keep their slot until they finish.
+	MaxComputations int
+	// SVGLimits bounds the complexity of the uploaded designs.
+	SVGLimits svg.Limits
+}
+
+// Validate checks that the server configuration is consistent.
+func (sc ServerConfig) Validate() error {
+	if sc.Addr == "" {
+		return errors.New("empty listen address")
+	}
+	if sc.Templates == nil {
+		return errors.New("no templates")
+	}
+	if (sc.TLSCertFile == "") != (sc.TLSKeyFile == "") {
+		return errors.New("both TLS certificate and key files must be set")
+	}
+	if sc.ReadTimeout < 0 || sc.WriteTimeout < 0 || sc.IdleTimeout < 0 || sc.ShutdownTimeout < 0 ||
+		sc.ComputeTimeout < 0 {
+		return errors.New("timeouts cannot be negative")
+	}
+	if sc.MaxUploadBytes < 0 || sc.MaxComputations < 0 || sc.SVGLimits.MaxElements < 0 || sc.SVGLimits.MaxDepth < 0 ||
+		sc.SVGLimits.MaxPathCommands < 0 {
+		return errors.New("limits cannot be negative")
+	}
+	return nil
+}
+
+// Run serves the API until ctx is done, then shuts the server down gracefully.
+func (a API) Run(ctx context.Context) error {
+	r, err := a.router()
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              a.server.Addr,
+		Handler:           r,
+		ReadTimeout:       a.server.ReadTimeout,
+		ReadHeaderTimeout: a.server.ReadTimeout,
+		WriteTimeout:      a.server.WriteTimeout,
+		IdleTimeout:       a.server.IdleTimeout,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		fmt.Printf("listening on %s\n", a.server.Addr)
+		if a.server.TLSCertFile != "" {
+			serveErr <- srv.ListenAndServeTLS(a.server.TLSCertFile, a.server.TLSKeyFile)
+		} else {
+			serveErr <- srv.ListenAndServe()
+		}
+	}()
+	a.ready.Store(true)
+
+	select {
+	case err := <-serveErr:
+		a.ready.Store(false)
+		return err
+	case <-ctx.Done():
+	}
+
+	a.ready.Store(false)
+	fmt.Println("shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutting down server: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// healthz reports that the process is alive.
+func (a API) healthz() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// readyz reports whether the server can handle requests: templates are loaded,
+// the configuration is valid and the server is not shutting down.
+func (a API) readyz() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.ready.Load() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready"})
+			return
+		}
+		if err := a.config.Get().Validate(); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "invalid configuration", "error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+	}
+}
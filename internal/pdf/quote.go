@@ -0,0 +1,257 @@
+// Package pdf renders the documents handed to customers.
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/go-pdf/fpdf"
+)
+
+const (
+	margin        = 15.0
+	previewHeight = 80.0
+	lineHeight    = 6.0
+	dateLayout    = "2006-01-02"
+)
+
+// strokeColors are the colors of the neon groups in the preview, the cut outline is grey.
+var strokeColors = [][3]int{
+	{230, 25, 75}, {0, 130, 200}, {60, 180, 75}, {245, 130, 48}, {145, 30, 180}, {70, 200, 200},
+}
+
+// Quote is the content of a quote document.
+type Quote struct {
+	Quote domain.Quote
+	Lines []domain.QuoteLine
+	// Preview holds the strokes of the design, by group id.
+	Preview  map[string][]svg.Polyline
+	Branding conf.Branding
+}
+
+// WriteQuote renders the quote as an A4 pdf document.
+func WriteQuote(w io.Writer, q Quote) error {
+	doc := fpdf.New("P", "mm", "A4", "")
+	doc.SetMargins(margin, margin, margin)
+	doc.SetAutoPageBreak(true, margin)
+	doc.SetTitle(fmt.Sprintf("Quote %s", q.reference()), true)
+	doc.SetCreator("neon-pricer", true)
+	doc.AliasNbPages("")
+	tr := doc.UnicodeTranslatorFromDescriptor("")
+	doc.SetFooterFunc(func() {
+		doc.SetY(-margin + 5)
+		doc.SetFont("Helvetica", "I", 8)
+		doc.SetTextColor(128, 128, 128)
+		doc.CellFormat(0, 5, tr(fmt.Sprintf("%s - page %d/{nb}", q.Branding.Company, doc.PageNo())),
+			"", 0, "C", false, 0, "")
+	})
+	doc.AddPage()
+
+	q.header(doc, tr)
+	q.preview(doc)
+	q.dimensions(doc, tr)
+	q.lines(doc, tr)
+	q.terms(doc, tr)
+
+	if err := doc.Error(); err != nil {
+		return fmt.Errorf("rendering pdf: %w", err)
+	}
+	return doc.Output(w)
+}
+
+// reference returns the quote id, or draft if the quote was not saved.
+func (q Quote) reference() string {
+	if q.Quote.ID == "" {
+		return "draft"
+	}
+	return q.Quote.ID
+}
+
+func (q Quote) money(amount float64) string {
+	return fmt.Sprintf("%.2f %s", amount, q.Branding.Currency)
+}
+
+func (q Quote) header(doc *fpdf.Fpdf, tr func(string) string) {
+	pageWidth, _ := doc.GetPageSize()
+	top := doc.GetY()
+
+	doc.SetFont("Helvetica", "B", 16)
+	doc.CellFormat(0, 8, tr(q.Branding.Company), "", 1, "L", false, 0, "")
+	doc.SetFont("Helvetica", "", 10)
+	for _, line := range strings.Split(q.Branding.Address, "\n") {
+		doc.CellFormat(90, 5, tr(line), "", 1, "L", false, 0, "")
+	}
+	if q.Branding.Contact != "" {
+		doc.CellFormat(90, 5, tr(q.Branding.Contact), "", 1, "L", false, 0, "")
+	}
+	bottom := doc.GetY()
+
+	right := pageWidth - margin - 70
+	doc.SetXY(right, top)
+	doc.SetFont("Helvetica", "B", 16)
+	doc.CellFormat(70, 8, "QUOTE", "", 2, "R", false, 0, "")
+	doc.SetFont("Helvetica", "", 10)
+	created := q.Quote.CreatedAt
+	details := []string{
+		fmt.Sprintf("Reference: %s", q.reference()),
+		fmt.Sprintf("Date: %s", created.Format(dateLayout)),
+		fmt.Sprintf("Valid until: %s", created.AddDate(0, 0, q.Branding.ValidityDays).Format(dateLayout)),
+	}
+	if q.Quote.FileName != "" {
+		details = append(details, fmt.Sprintf("Design: %s", q.Quote.FileName))
+	}
+	for _, detail := range details {
+		doc.CellFormat(70, 5, tr(detail), "", 2, "R", false, 0, "")
+	}
+
+	doc.SetY(math.Max(bottom, doc.GetY()) + lineHeight)
+}
+
+// preview draws the strokes of the design scaled to fit the page width.
+func (q Quote) preview(doc *fpdf.Fpdf) {
+	var bounds svg.Bounds
+	first := true
+	groups := make([]string, 0, len(q.Preview))
+	for group, polylines := range q.Preview {
+		groups = append(groups, group)
+		for _, pl := range polylines {
+			if len(pl) == 0 {
+				continue
+			}
+			if first {
+				bounds, first = pl.Bounds(), false
+			} else {
+				bounds = bounds.Expand(pl.Bounds())
+			}
+		}
+	}
+	if first || bounds.Width() == 0 && bounds.Height() == 0 {
+		return
+	}
+	slices.Sort(groups)
+
+	pageWidth, _ := doc.GetPageSize()
+	boxWidth := pageWidth - 2*margin
+	top := doc.GetY()
+	scale := math.Inf(1)
+	if bounds.Width() > 0 {
+		scale = boxWidth / bounds.Width()
+	}
+	if bounds.Height() > 0 {
+		scale = math.Min(scale, previewHeight/bounds.Height())
+	}
+	offsetX := margin + (boxWidth-bounds.Width()*scale)/2
+	offsetY := top + (previewHeight-bounds.Height()*scale)/2
+	origin := bounds.Min()
+
+	doc.SetDrawColor(220, 220, 220)
+	doc.SetLineWidth(0.2)
+	doc.Rect(margin, top, boxWidth, previewHeight, "D")
+	color := 0
+	for _, group := range groups {
+		if strings.EqualFold(group, "DECOUPE") {
+			doc.SetDrawColor(150, 150, 150)
+			doc.SetLineWidth(0.2)
+		} else {
+			c := strokeColors[color%len(strokeColors)]
+			color++
+			doc.SetDrawColor(c[0], c[1], c[2])
+			doc.SetLineWidth(0.6)
+		}
+		for _, pl := range q.Preview[group] {
+			for i, p := range pl {
+				x, y := offsetX+(p.X-origin.X)*scale, offsetY+(p.Y-origin.Y)*scale
+				if i == 0 {
+					doc.MoveTo(x, y)
+				} else {
+					doc.LineTo(x, y)
+				}
+			}
+			doc.DrawPath("D")
+		}
+	}
+	doc.SetDrawColor(0, 0, 0)
+	doc.SetLineWidth(0.2)
+	doc.SetY(top + previewHeight + lineHeight)
+}
+
+func (q Quote) dimensions(doc *fpdf.Fpdf, tr func(string) string) {
+	groups := make([]string, 0, len(q.Quote.Sizes))
+	for g := range q.Quote.Sizes {
+		groups = append(groups, g)
+	}
+	slices.Sort(groups)
+
+	title(doc, "Dimensions")
+	widths := []float64{60, 40, 40, 40}
+	tableHeader(doc, tr, widths, []string{"Layer", "Length (mm)", "Width (mm)", "Height (mm)"}, "LRRR")
+	for _, g := range groups {
+		size := q.Quote.Sizes[g]
+		doc.CellFormat(widths[0], lineHeight, tr(g), "B", 0, "L", false, 0, "")
+		doc.CellFormat(widths[1], lineHeight, fmt.Sprintf("%.0f", size.Length), "B", 0, "R", false, 0, "")
+		doc.CellFormat(widths[2], lineHeight, fmt.Sprintf("%.0f", size.Width), "B", 0, "R", false, 0, "")
+		doc.CellFormat(widths[3], lineHeight, fmt.Sprintf("%.0f", size.Height), "B", 1, "R", false, 0, "")
+	}
+	doc.Ln(lineHeight)
+}
+
+func (q Quote) lines(doc *fpdf.Fpdf, tr func(string) string) {
+	title(doc, "Items")
+	widths := []float64{35, 45, 25, 15, 30, 30}
+	tableHeader(doc, tr, widths, []string{"Layer", "Description", "Quantity", "Unit", "Unit price", "Amount"}, "LLRLRR")
+	for _, line := range q.Lines {
+		doc.CellFormat(widths[0], lineHeight, tr(line.Group), "B", 0, "L", false, 0, "")
+		doc.CellFormat(widths[1], lineHeight, tr(line.Description), "B", 0, "L", false, 0, "")
+		doc.CellFormat(widths[2], lineHeight, fmt.Sprintf("%.2f", line.Quantity), "B", 0, "R", false, 0, "")
+		doc.CellFormat(widths[3], lineHeight, tr(line.Unit), "B", 0, "L", false, 0, "")
+		doc.CellFormat(widths[4], lineHeight, tr(q.money(line.UnitPrice)), "B", 0, "R", false, 0, "")
+		doc.CellFormat(widths[5], lineHeight, tr(q.money(line.Amount)), "B", 1, "R", false, 0, "")
+	}
+
+	var labelWidth float64
+	for _, w := range widths[:len(widths)-1] {
+		labelWidth += w
+	}
+	if copies := q.Quote.Copies(); copies > 1 {
+		doc.CellFormat(labelWidth, lineHeight, "Price per sign", "", 0, "R", false, 0, "")
+		doc.CellFormat(widths[len(widths)-1], lineHeight, tr(q.money(q.Quote.Prices.Total())), "", 1, "R", false, 0, "")
+		doc.CellFormat(labelWidth, lineHeight, "Quantity", "", 0, "R", false, 0, "")
+		doc.CellFormat(widths[len(widths)-1], lineHeight, fmt.Sprintf("%d", copies), "", 1, "R", false, 0, "")
+	}
+	doc.SetFont("Helvetica", "B", 11)
+	doc.CellFormat(labelWidth, lineHeight+2, "Total", "", 0, "R", false, 0, "")
+	doc.CellFormat(widths[len(widths)-1], lineHeight+2, tr(q.money(q.Quote.Total)), "", 1, "R", false, 0, "")
+	doc.Ln(lineHeight)
+}
+
+func (q Quote) terms(doc *fpdf.Fpdf, tr func(string) string) {
+	if q.Branding.Terms == "" {
+		return
+	}
+	title(doc, "Terms")
+	doc.SetFont("Helvetica", "", 9)
+	doc.MultiCell(0, 4.5, tr(q.Branding.Terms), "", "L", false)
+}
+
+func title(doc *fpdf.Fpdf, text string) {
+	doc.SetFont("Helvetica", "B", 12)
+	doc.CellFormat(0, 8, text, "", 1, "L", false, 0, "")
+}
+
+// tableHeader writes the labels of the columns, aligns holds the alignment of each column.
+func tableHeader(doc *fpdf.Fpdf, tr func(string) string, widths []float64, labels []string, aligns string) {
+	doc.SetFont("Helvetica", "B", 10)
+	doc.SetFillColor(235, 235, 235)
+	for i, label := range labels {
+		doc.CellFormat(widths[i], lineHeight+1, tr(label), "B", 0, aligns[i:i+1], true, 0, "")
+	}
+	doc.Ln(-1)
+	doc.SetFont("Helvetica", "", 10)
+}
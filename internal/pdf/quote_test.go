@@ -0,0 +1,70 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagesCount matches the number of pages of the page tree of a pdf document.
+var pagesCount = regexp.MustCompile(`/Type /Pages\s*/Kids \[[^\]]*\]\s*/Count (\d+)`)
+
+func Test_WriteQuote(t *testing.T) {
+	lines := func(n int) []domain.QuoteLine {
+		lines := make([]domain.QuoteLine, n)
+		for i := range lines {
+			lines[i] = domain.QuoteLine{Group: "6MM", Description: fmt.Sprintf("Item %d", i), Quantity: 1, Unit: "m", UnitPrice: 1, Amount: 1}
+		}
+		return lines
+	}
+	quote := domain.Quote{
+		ID:        "abc",
+		CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		FileName:  "sign.svg",
+		Sizes:     map[string]domain.Size{"6MM": {Length: 1000, Width: 200, Height: 100}},
+		Prices:    domain.Price{"6MM": {SiliconePrice: 1}},
+		Total:     1,
+	}
+	branding := conf.Branding{Company: "Neon & Co", Currency: "EUR", ValidityDays: 30, Terms: "Paid on order."}
+	preview := map[string][]svg.Polyline{"6MM": {{{X: 0, Y: 0}, {X: 200, Y: 100}}}}
+
+	tests := map[string]struct {
+		quote     Quote
+		wantPages int
+	}{
+		"one page": {
+			quote:     Quote{Quote: quote, Lines: lines(3), Preview: preview, Branding: branding},
+			wantPages: 1,
+		},
+		"lines flowing onto a second page": {
+			quote:     Quote{Quote: quote, Lines: lines(40), Preview: preview, Branding: branding},
+			wantPages: 2,
+		},
+		"without preview": {
+			quote:     Quote{Quote: quote, Lines: lines(3), Branding: branding},
+			wantPages: 1,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, WriteQuote(&buf, tt.quote))
+			assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+			match := pagesCount.FindSubmatch(buf.Bytes())
+			require.NotNil(t, match, "page tree")
+			pages, err := strconv.Atoi(string(match[1]))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPages, pages)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"theo303/neon-pricer/internal/svg"
+)
+
+// Palette holds the colors given to the groups of a design, in order.
+var Palette = []string{"#e6194b", "#0082c8", "#3cb44b", "#f58230", "#911eb4", "#46c8c8", "#f032e6", "#808000"}
+
+const skippedColor = "#ff0000"
+
+// AnnotatedStroke is a measured form of a design.
+type AnnotatedStroke struct {
+	Polylines []svg.Polyline
+	LengthMm  float64
+}
+
+// AnnotatedGroup is a measured group of a design.
+type AnnotatedGroup struct {
+	ID       string
+	Strokes  []AnnotatedStroke
+	LengthMm float64
+}
+
+// Annotations holds the measures drawn over a design.
+type Annotations struct {
+	Groups  []AnnotatedGroup
+	Skipped []svg.Skipped
+}
+
+// Annotate writes the svg source with the annotations drawn over it: the strokes of each group
+// highlighted with their start points and lengths, the bounds of the groups, and the skipped elements in red.
+func Annotate(w io.Writer, source []byte, a Annotations) error {
+	end := bytes.LastIndex(source, []byte("</svg>"))
+	if end < 0 {
+		return errors.New("closing svg tag not found")
+	}
+
+	var bounds svg.Bounds
+	found := false
+	for _, g := range a.Groups {
+		if b, ok := g.bounds(); ok {
+			if !found {
+				bounds, found = b, true
+			} else {
+				bounds = bounds.Expand(b)
+			}
+		}
+	}
+	// sizes are relative to the design, so annotations stay readable whatever its units.
+	size := 100.0
+	if found {
+		size = math.Max(math.Hypot(bounds.Width(), bounds.Height()), 1)
+	}
+	stroke, marker, font := size/400, size/200, size/70
+
+	var buf bytes.Buffer
+	buf.WriteString(`<g id="annotations" font-family="sans-serif">` + "\n")
+	for i, g := range a.Groups {
+		color := Palette[i%len(Palette)]
+		fmt.Fprintf(&buf, `<g id="annotations-%s" fill="none" stroke="%s">`+"\n", escape(g.ID), color)
+		if b, ok := g.bounds(); ok {
+			corner := b.Min()
+			fmt.Fprintf(&buf, `<rect x="%s" y="%s" width="%s" height="%s" stroke-width="%s" stroke-dasharray="%s %s"/>`+"\n",
+				num(corner.X), num(corner.Y), num(b.Width()), num(b.Height()), num(stroke), num(marker*2), num(marker))
+			fmt.Fprintf(&buf, `<text x="%s" y="%s" font-size="%s" fill="%s" stroke="none">%s: %.0f mm</text>`+"\n",
+				num(corner.X), num(corner.Y-font/2), num(font), color, escape(g.ID), g.LengthMm)
+		}
+		for n, s := range g.Strokes {
+			for _, pl := range s.Polylines {
+				if len(pl) == 0 {
+					continue
+				}
+				points := make([]string, len(pl))
+				for j, p := range pl {
+					points[j] = num(p.X) + "," + num(p.Y)
+				}
+				fmt.Fprintf(&buf, `<polyline points="%s" stroke-width="%s" stroke-opacity="0.7"/>`+"\n",
+					strings.Join(points, " "), num(stroke*2))
+				fmt.Fprintf(&buf, `<circle cx="%s" cy="%s" r="%s" fill="%s" stroke="none"/>`+"\n",
+					num(pl[0].X), num(pl[0].Y), num(marker), color)
+			}
+			if len(s.Polylines) == 0 || len(s.Polylines[0]) == 0 {
+				continue
+			}
+			start := s.Polylines[0][0]
+			fmt.Fprintf(&buf, `<text x="%s" y="%s" font-size="%s" fill="%s" stroke="none">#%d %.0f mm</text>`+"\n",
+				num(start.X+marker*1.5), num(start.Y-marker*1.5), num(font*0.7), color, n+1, s.LengthMm)
+		}
+		buf.WriteString("</g>\n")
+	}
+
+	if len(a.Skipped) > 0 {
+		style := fmt.Sprintf("fill:none;stroke:%s;stroke-width:%s;stroke-dasharray:%s", skippedColor, num(stroke*2), num(marker))
+		textStyle := fmt.Sprintf("fill:%s;stroke:none", skippedColor)
+		buf.WriteString(`<g id="annotations-skipped">` + "\n")
+		for _, s := range a.Skipped {
+			fmt.Fprintf(&buf, "<g><title>skipped %s: %s</title>", escape(s.Element), escape(s.Reason))
+			if s.Element == "text" {
+				buf.WriteString(s.Markup(textStyle))
+			} else {
+				buf.WriteString(s.Markup(style))
+			}
+			buf.WriteString("</g>\n")
+		}
+		buf.WriteString("</g>\n")
+	}
+	buf.WriteString("</g>\n")
+
+	for _, part := range [][]byte{source[:end], buf.Bytes(), source[end:]} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g AnnotatedGroup) bounds() (svg.Bounds, bool) {
+	var bounds svg.Bounds
+	found := false
+	for _, s := range g.Strokes {
+		for _, pl := range s.Polylines {
+			if len(pl) == 0 {
+				continue
+			}
+			if !found {
+				bounds, found = pl.Bounds(), true
+			} else {
+				bounds = bounds.Expand(pl.Bounds())
+			}
+		}
+	}
+	return bounds, found
+}
+
+// num formats a coordinate with a precision good enough for drawing.
+func num(f float64) string {
+	// adding 0 turns -0 into 0.
+	return strconv.FormatFloat(math.Round(f*100)/100+0, 'f', -1, 64)
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Annotate(t *testing.T) {
+	source := `<svg><g id="6MM"><line x1="0" y1="0" x2="100" y2="0"/></g><g id="8MM"><polygon points="0,0 1,1"/></g></svg>`
+	parsed, err := svg.Parse(strings.NewReader(source), "", svg.Limits{})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = Annotate(&buf, []byte(source), Annotations{
+		Groups: []AnnotatedGroup{{
+			ID:       "6MM",
+			Strokes:  []AnnotatedStroke{{Polylines: []svg.Polyline{{{X: 0, Y: 0}, {X: 100, Y: 0}}}, LengthMm: 35.3}},
+			LengthMm: 35.3,
+		}},
+		Skipped: parsed.Skipped,
+	})
+	require.NoError(t, err)
+
+	got := buf.String()
+	assert.True(t, strings.HasPrefix(got, `<svg><g id="6MM">`), "the artwork is kept")
+	assert.True(t, strings.HasSuffix(got, "</g>\n</svg>"), "the annotations are drawn over the artwork")
+	assert.Contains(t, got, `<polyline points="0,0 100,0"`)
+	assert.Contains(t, got, "6MM: 35 mm")
+	assert.Contains(t, got, "#1 35 mm")
+	assert.Contains(t, got, "<title>skipped polygon: unsupported element</title><polygon points=\"0,0 1,1\" style=\"fill:none;stroke:#ff0000")
+
+	assert.Error(t, Annotate(&buf, []byte("<svg>"), Annotations{}))
+}
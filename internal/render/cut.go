@@ -0,0 +1,257 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"theo303/neon-pricer/internal/svg"
+)
+
+// cutColor and cutStroke are the color and the width in mm of the lines cut by the laser.
+const (
+	cutColor  = "#ff0000"
+	cutStroke = 0.1
+)
+
+// Layers of the dxf cut files.
+const (
+	dxfCutLayer  = "CUT"
+	dxfHoleLayer = "HOLES"
+)
+
+// CutHole is a hole drilled in the backing, in mm.
+type CutHole struct {
+	Center     svg.Point
+	DiameterMm float64
+}
+
+// CutSheet is the backing cut from a plexi sheet, in mm from its top left corner.
+type CutSheet struct {
+	WidthMm, HeightMm float64
+	Outlines          []svg.Contour
+	Holes             []CutHole
+	// ToleranceMm is the positive tolerance the curves a format cannot hold are flattened to.
+	ToleranceMm float64
+}
+
+// CutFile writes the sheet as a svg in millimetres, its outlines and holes drawn as hairlines.
+// Outlines made of lines are written as polygons, the other ones as paths keeping their curves.
+func CutFile(w io.Writer, s CutSheet) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%smm" height="%smm" viewBox="0 0 %s %s">`+"\n",
+		num(s.WidthMm), num(s.HeightMm), num(s.WidthMm), num(s.HeightMm))
+	fmt.Fprintf(&b, `<g id="cut" fill="none" stroke="%s" stroke-width="%s">`+"\n", cutColor, num(cutStroke))
+	for _, c := range s.Outlines {
+		if len(c) == 0 {
+			continue
+		}
+		if isCircle(c) {
+			fmt.Fprintf(&b, `<circle cx="%s" cy="%s" r="%s"/>`+"\n", num(c[0].Center.X), num(c[0].Center.Y), num(c[0].RX))
+			continue
+		}
+		if !onlyLines(c) {
+			fmt.Fprintf(&b, `<path d="%s"/>`+"\n", pathData(c))
+			continue
+		}
+		points := []string{num(c[0].Start.X) + "," + num(c[0].Start.Y)}
+		for _, seg := range c {
+			points = append(points, num(seg.End.X)+","+num(seg.End.Y))
+		}
+		element := "polyline"
+		if c.Closed() {
+			element, points = "polygon", points[:len(points)-1]
+		}
+		fmt.Fprintf(&b, `<%s points="%s"/>`+"\n", element, strings.Join(points, " "))
+	}
+	for _, h := range s.Holes {
+		fmt.Fprintf(&b, `<circle cx="%s" cy="%s" r="%s"/>`+"\n", num(h.Center.X), num(h.Center.Y), num(h.DiameterMm/2))
+	}
+	b.WriteString("</g>\n</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// isCircle reports whether the contour is a whole circle.
+func isCircle(c svg.Contour) bool {
+	return len(c) == 1 && c[0].Circular() && math.Abs(c[0].Sweep) >= 2*math.Pi-1e-9
+}
+
+func onlyLines(c svg.Contour) bool {
+	for _, s := range c {
+		if s.Kind != svg.SegmentLine {
+			return false
+		}
+	}
+	return true
+}
+
+// pathData returns the svg path commands drawing the contour,
+// their parameters separated by commas to be read back by the svg package.
+func pathData(c svg.Contour) string {
+	var d strings.Builder
+	pt := func(p svg.Point) string { return num(p.X) + "," + num(p.Y) }
+	fmt.Fprintf(&d, "M%s", pt(c[0].Start))
+	for i, s := range c {
+		switch s.Kind {
+		case svg.SegmentArc:
+			for _, half := range splitFullArc(s) {
+				large, sweep := 0, 0
+				if math.Abs(half.Sweep) > math.Pi {
+					large = 1
+				}
+				if half.Sweep > 0 {
+					sweep = 1
+				}
+				fmt.Fprintf(&d, " A%s,%s,%s,%d,%d,%s",
+					num(half.RX), num(half.RY), num(half.Rotation*180/math.Pi), large, sweep, pt(half.End))
+			}
+		case svg.SegmentBezier:
+			fmt.Fprintf(&d, " C%s,%s,%s", pt(s.Controls[0]), pt(s.Controls[1]), pt(s.End))
+		default:
+			// the closing line is drawn by Z.
+			if i == len(c)-1 && c.Closed() {
+				continue
+			}
+			fmt.Fprintf(&d, " L%s", pt(s.End))
+		}
+	}
+	if c.Closed() {
+		d.WriteString(" Z")
+	}
+	return d.String()
+}
+
+// splitFullArc splits the arcs going all around their ellipse in halves, as an arc cannot end where it starts.
+func splitFullArc(s svg.Segment) []svg.Segment {
+	if math.Abs(s.Sweep) < 2*math.Pi-1e-9 {
+		return []svg.Segment{s}
+	}
+	first, second := s, s
+	first.Sweep, second.Sweep = s.Sweep/2, s.Sweep/2
+	first.End = s.ArcPoint(s.StartAngle + s.Sweep/2)
+	second.Start, second.StartAngle = first.End, s.StartAngle+s.Sweep/2
+	return []svg.Segment{first, second}
+}
+
+// CutDXF writes the sheet as an ASCII dxf R12 in millimetres, the outlines on the CUT layer and the holes
+// on the HOLES one. The y-axis of dxf going up, the sheet is flipped to keep its bottom left corner at 0,0.
+// Circular arcs are kept as bulges of polylines, the elliptical arcs and the bezier curves are flattened.
+func CutDXF(w io.Writer, s CutSheet) error {
+	d := dxfWriter{}
+	flip := func(p svg.Point) svg.Point { return svg.Point{X: p.X, Y: s.HeightMm - p.Y} }
+
+	d.group(0, "SECTION")
+	d.group(2, "HEADER")
+	d.group(9, "$ACADVER")
+	d.group(1, "AC1009")
+	// $INSUNITS 4 declares millimetres to the cutters reading it.
+	d.group(9, "$INSUNITS")
+	d.group(70, "4")
+	d.group(9, "$EXTMIN")
+	d.point(10, svg.Point{})
+	d.group(9, "$EXTMAX")
+	d.point(10, svg.Point{X: s.WidthMm, Y: s.HeightMm})
+	d.group(0, "ENDSEC")
+
+	d.group(0, "SECTION")
+	d.group(2, "ENTITIES")
+	for _, c := range s.Outlines {
+		if len(c) == 0 {
+			continue
+		}
+		if isCircle(c) {
+			d.circle(dxfCutLayer, flip(c[0].Center), c[0].RX)
+			continue
+		}
+		var vertices []dxfVertex
+		for _, seg := range c {
+			switch {
+			case seg.Circular():
+				for _, half := range splitFullArc(seg) {
+					// flipping the y-axis turns the arc the other way.
+					vertices = append(vertices, dxfVertex{point: flip(half.Start), bulge: math.Tan(-half.Sweep / 4)})
+				}
+			case seg.Kind == svg.SegmentLine:
+				vertices = append(vertices, dxfVertex{point: flip(seg.Start)})
+			default:
+				pl := svg.Contour{seg}.Flatten(s.ToleranceMm)
+				for _, p := range pl[:len(pl)-1] {
+					vertices = append(vertices, dxfVertex{point: flip(p)})
+				}
+			}
+		}
+		closed := c.Closed()
+		if !closed {
+			vertices = append(vertices, dxfVertex{point: flip(c[len(c)-1].End)})
+		}
+		d.polyline(dxfCutLayer, vertices, closed)
+	}
+	for _, h := range s.Holes {
+		d.circle(dxfHoleLayer, flip(h.Center), h.DiameterMm/2)
+	}
+	d.group(0, "ENDSEC")
+	d.group(0, "EOF")
+	_, err := io.WriteString(w, d.String())
+	return err
+}
+
+// dxfVertex is a vertex of a dxf polyline, bulge being the tangent of a quarter of the angle
+// of the arc going to the next vertex, positive counterclockwise.
+type dxfVertex struct {
+	point svg.Point
+	bulge float64
+}
+
+// dxfWriter writes the group codes and values of a dxf file.
+type dxfWriter struct {
+	strings.Builder
+}
+
+func (d *dxfWriter) group(code int, value string) {
+	fmt.Fprintf(d, "%d\n%s\n", code, value)
+}
+
+// point writes the coordinates of p with the group code of x, followed by a zero z.
+func (d *dxfWriter) point(code int, p svg.Point) {
+	d.group(code, dxfNum(p.X))
+	d.group(code+10, dxfNum(p.Y))
+	d.group(code+20, "0")
+}
+
+func (d *dxfWriter) circle(layer string, center svg.Point, r float64) {
+	d.group(0, "CIRCLE")
+	d.group(8, layer)
+	d.point(10, center)
+	d.group(40, dxfNum(r))
+}
+
+func (d *dxfWriter) polyline(layer string, vertices []dxfVertex, closed bool) {
+	flags := "0"
+	if closed {
+		flags = "1"
+	}
+	d.group(0, "POLYLINE")
+	d.group(8, layer)
+	d.group(66, "1")
+	d.point(10, svg.Point{})
+	d.group(70, flags)
+	for _, v := range vertices {
+		d.group(0, "VERTEX")
+		d.group(8, layer)
+		d.point(10, v.point)
+		if v.bulge != 0 {
+			d.group(42, strconv.FormatFloat(v.bulge, 'f', 6, 64))
+		}
+	}
+	d.group(0, "SEQEND")
+	d.group(8, layer)
+}
+
+// dxfNum formats lengths in mm to the micrometre.
+func dxfNum(f float64) string {
+	// adding 0 turns -0 into 0.
+	return strconv.FormatFloat(math.Round(f*1000)/1000+0, 'f', -1, 64)
+}
@@ -0,0 +1,72 @@
+package render
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CutSheet(t *testing.T) {
+	circle := svg.Contour{{Kind: svg.SegmentArc, Start: svg.Point{X: 60, Y: 25}, End: svg.Point{X: 60, Y: 25},
+		Center: svg.Point{X: 50, Y: 25}, RX: 10, RY: 10, Sweep: 2 * math.Pi}}
+	// a slot: a line closed by a half circle going through the bottom, and a line back.
+	slot := svg.Contour{
+		{Kind: svg.SegmentLine, Start: svg.Point{X: 0, Y: 0}, End: svg.Point{X: 20, Y: 0}},
+		{Kind: svg.SegmentArc, Start: svg.Point{X: 20, Y: 0}, End: svg.Point{X: 20, Y: 10},
+			Center: svg.Point{X: 20, Y: 5}, RX: 5, RY: 5, StartAngle: -math.Pi / 2, Sweep: math.Pi},
+		{Kind: svg.SegmentLine, Start: svg.Point{X: 20, Y: 10}, End: svg.Point{X: 0, Y: 10}},
+	}
+	sheet := CutSheet{
+		WidthMm: 100, HeightMm: 50,
+		Outlines:    []svg.Contour{circle, slot},
+		Holes:       []CutHole{{Center: svg.Point{X: 10, Y: 40}, DiameterMm: 8}},
+		ToleranceMm: 0.1,
+	}
+	tests := map[string]struct {
+		write func(*bytes.Buffer, CutSheet) error
+		want  []string
+	}{
+		"svg": {
+			write: func(b *bytes.Buffer, s CutSheet) error { return CutFile(b, s) },
+			want: []string{
+				`<circle cx="50" cy="25" r="10"/>`,
+				`<path d="M0,0 L20,0 A5,5,0,0,1,20,10 L0,10"/>`,
+				`<circle cx="10" cy="40" r="4"/>`,
+			},
+		},
+		"dxf": {
+			write: func(b *bytes.Buffer, s CutSheet) error { return CutDXF(b, s) },
+			want: []string{
+				"0\nCIRCLE\n8\nCUT\n10\n50\n20\n25\n30\n0\n40\n10\n",
+				// the y-axis is flipped, the half circle turns clockwise.
+				"0\nVERTEX\n8\nCUT\n10\n0\n20\n50\n30\n0\n0\nVERTEX\n8\nCUT\n10\n20\n20\n50\n30\n0\n42\n-1.000000\n",
+				"10\n0\n20\n40\n30\n0\n0\nSEQEND\n",
+				"0\nCIRCLE\n8\nHOLES\n10\n10\n20\n10\n30\n0\n40\n4\n",
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, tt.write(&buf, sheet))
+			for _, want := range tt.want {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func Test_CutDXF_sections(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, CutDXF(&buf, CutSheet{WidthMm: 10, HeightMm: 10}))
+	got := buf.String()
+	assert.True(t, strings.HasPrefix(got, "0\nSECTION\n2\nHEADER\n9\n$ACADVER\n1\nAC1009\n"))
+	assert.Contains(t, got, "0\nSECTION\n2\nENTITIES\n0\nENDSEC\n")
+	assert.True(t, strings.HasSuffix(got, "0\nEOF\n"))
+}
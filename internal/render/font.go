@@ -0,0 +1,43 @@
+package render
+
+import "image/color"
+
+// glyphs is a minimal bitmap font for the labels of the scale bar.
+var glyphs = map[rune][]string{
+	'0': {"111", "101", "101", "101", "111"},
+	'1': {"010", "110", "010", "010", "111"},
+	'2': {"111", "001", "111", "100", "111"},
+	'3': {"111", "001", "111", "001", "111"},
+	'4': {"101", "101", "111", "001", "001"},
+	'5': {"111", "100", "111", "001", "111"},
+	'6': {"111", "100", "111", "101", "111"},
+	'7': {"111", "001", "001", "001", "001"},
+	'8': {"111", "101", "111", "101", "111"},
+	'9': {"111", "101", "111", "001", "111"},
+	'.': {"0", "0", "0", "0", "1"},
+	'm': {"00000", "11110", "10101", "10101", "10101"},
+	' ': {"00", "00", "00", "00", "00"},
+}
+
+// text draws the string with its top left corner at x, y, each font pixel being size pixels wide.
+func (c *canvas) text(x, y int, s string, size int, col color.RGBA) {
+	for _, r := range s {
+		glyph, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+		for row, line := range glyph {
+			for column, bit := range line {
+				if bit != '1' {
+					continue
+				}
+				for dy := 0; dy < size; dy++ {
+					for dx := 0; dx < size; dx++ {
+						c.set(x+column*size+dx, y+row*size+dy, col)
+					}
+				}
+			}
+		}
+		x += (len(glyph[0]) + 1) * size
+	}
+}
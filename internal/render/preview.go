@@ -0,0 +1,278 @@
+// Package render draws images of the designs shown to customers.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+	"strconv"
+
+	"theo303/neon-pricer/internal/svg"
+)
+
+// ErrEmptyDesign is returned when a design has nothing to draw.
+var ErrEmptyDesign = errors.New("nothing to draw")
+
+var (
+	// Dark is the background of the previews.
+	Dark = color.RGBA{R: 17, G: 17, B: 20, A: 255}
+	// ClearPlexi is used for the backing when the plexi has no color.
+	ClearPlexi = color.RGBA{R: 40, G: 44, B: 48, A: 255}
+	// WarmWhite is used for the layers lit by LEDs without color.
+	WarmWhite = color.RGBA{R: 255, G: 214, B: 170, A: 255}
+)
+
+// Layer is a group of strokes lit by the same LED.
+type Layer struct {
+	Polylines []svg.Polyline
+	Color     color.RGBA
+	// Thickness is the width of the silicone, in design units.
+	Thickness float64
+}
+
+// Design holds what is drawn on a preview.
+type Design struct {
+	Layers []Layer
+	// Backing holds the closed outlines of the plexi, the design is drawn on a dark background if empty.
+	Backing      []svg.Polyline
+	BackingColor color.RGBA
+	// UnitsPerMm converts design units to millimeters for the scale bar.
+	UnitsPerMm float64
+}
+
+// Preview renders the design as glowing strokes in an image of the given width, the design being
+// fitted in a square of that width so that the height of the image is bounded too.
+func Preview(d Design, width int) (*image.RGBA, error) {
+	bounds, ok := d.bounds()
+	if !ok || bounds.Width() == 0 && bounds.Height() == 0 {
+		return nil, ErrEmptyDesign
+	}
+	if width <= 0 {
+		return nil, fmt.Errorf("invalid image width %d", width)
+	}
+
+	margin := float64(width) / 20
+	scale := (float64(width) - 2*margin) / math.Max(bounds.Width(), bounds.Height())
+	barHeight := 40.0
+	height := int(math.Ceil(bounds.Height()*scale + 2*margin + barHeight))
+	origin := bounds.Min()
+	toPx := func(p svg.Point) svg.Point {
+		return svg.Point{X: margin + (p.X-origin.X)*scale, Y: margin + (p.Y-origin.Y)*scale}
+	}
+
+	c := newCanvas(width, height, Dark)
+	if len(d.Backing) > 0 {
+		outlines := make([][]svg.Point, len(d.Backing))
+		for i, pl := range d.Backing {
+			outlines[i] = transform(pl, toPx, 1)
+		}
+		c.fillPolygons(outlines, d.BackingColor)
+	}
+	for _, layer := range d.Layers {
+		width := math.Max(layer.Thickness*scale, 2)
+		strokes := make([][]svg.Point, len(layer.Polylines))
+		for i, pl := range layer.Polylines {
+			strokes[i] = transform(pl, toPx, math.Max(width/8, 1))
+		}
+		c.glow(strokes, layer.Color, width)
+	}
+	if d.UnitsPerMm > 0 {
+		c.scaleBar(margin, float64(height)-barHeight/2, scale*d.UnitsPerMm, float64(width)/4)
+	}
+	return c.image(), nil
+}
+
+func (d Design) bounds() (svg.Bounds, bool) {
+	var bounds svg.Bounds
+	found := false
+	expand := func(polylines []svg.Polyline) {
+		for _, pl := range polylines {
+			if len(pl) == 0 {
+				continue
+			}
+			if !found {
+				bounds, found = pl.Bounds(), true
+			} else {
+				bounds = bounds.Expand(pl.Bounds())
+			}
+		}
+	}
+	expand(d.Backing)
+	for _, l := range d.Layers {
+		expand(l.Polylines)
+	}
+	return bounds, found
+}
+
+// transform converts the polyline to pixels, dropping the points closer than spacing pixels.
+func transform(pl svg.Polyline, toPx func(svg.Point) svg.Point, spacing float64) []svg.Point {
+	points := make([]svg.Point, 0, len(pl))
+	for i, p := range pl {
+		px := toPx(p)
+		if i > 0 && i < len(pl)-1 && math.Hypot(px.X-points[len(points)-1].X, px.Y-points[len(points)-1].Y) < spacing {
+			continue
+		}
+		points = append(points, px)
+	}
+	return points
+}
+
+// ParseHexColor parses a #rrggbb color.
+func ParseHexColor(s string) (color.RGBA, error) {
+	if len(s) != 7 || s[0] != '#' {
+		return color.RGBA{}, fmt.Errorf("color %q is not formatted as #rrggbb", s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("parsing color %q: %w", s, err)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
+
+// canvas accumulates light in floating point before being converted to an image.
+type canvas struct {
+	width, height int
+	pix           [][3]float64
+}
+
+func newCanvas(width, height int, background color.RGBA) *canvas {
+	c := &canvas{width: width, height: height, pix: make([][3]float64, width*height)}
+	for i := range c.pix {
+		c.pix[i] = [3]float64{float64(background.R), float64(background.G), float64(background.B)}
+	}
+	return c
+}
+
+func (c *canvas) set(x, y int, col color.RGBA) {
+	if x < 0 || y < 0 || x >= c.width || y >= c.height {
+		return
+	}
+	c.pix[y*c.width+x] = [3]float64{float64(col.R), float64(col.G), float64(col.B)}
+}
+
+// fillPolygons fills the area inside the outlines with the even-odd rule, so inner outlines are holes.
+func (c *canvas) fillPolygons(outlines [][]svg.Point, col color.RGBA) {
+	for y := 0; y < c.height; y++ {
+		cy := float64(y) + 0.5
+		var crossings []float64
+		for _, outline := range outlines {
+			for i := range outline {
+				a, b := outline[i], outline[(i+1)%len(outline)]
+				if (a.Y <= cy) == (b.Y <= cy) {
+					continue
+				}
+				crossings = append(crossings, a.X+(cy-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+			}
+		}
+		slices.Sort(crossings)
+		for i := 0; i+1 < len(crossings); i += 2 {
+			for x := int(math.Ceil(crossings[i] - 0.5)); float64(x)+0.5 <= crossings[i+1]; x++ {
+				c.set(x, y, col)
+			}
+		}
+	}
+}
+
+// glow draws the strokes with a core of the given width and a halo of light around it.
+func (c *canvas) glow(strokes [][]svg.Point, col color.RGBA, width float64) {
+	half := width / 2
+	radius := math.Max(width*2, 6)
+	reach := half + 2*radius
+
+	// distances holds the squared distance of each pixel to the closest stroke, within reach.
+	distances := make([]float64, len(c.pix))
+	for i := range distances {
+		distances[i] = math.Inf(1)
+	}
+	for _, stroke := range strokes {
+		for i := 0; i < len(stroke); i++ {
+			a, b := stroke[i], stroke[i]
+			if i+1 < len(stroke) {
+				b = stroke[i+1]
+			}
+			minX := max(int(math.Floor(math.Min(a.X, b.X)-reach)), 0)
+			maxX := min(int(math.Ceil(math.Max(a.X, b.X)+reach)), c.width-1)
+			minY := max(int(math.Floor(math.Min(a.Y, b.Y)-reach)), 0)
+			maxY := min(int(math.Ceil(math.Max(a.Y, b.Y)+reach)), c.height-1)
+			for y := minY; y <= maxY; y++ {
+				for x := minX; x <= maxX; x++ {
+					d := squaredDistanceToSegment(svg.Point{X: float64(x) + 0.5, Y: float64(y) + 0.5}, a, b)
+					if idx := y*c.width + x; d < distances[idx] {
+						distances[idx] = d
+					}
+				}
+			}
+		}
+	}
+
+	light := [3]float64{float64(col.R), float64(col.G), float64(col.B)}
+	// the core of the tube is brighter than its halo.
+	core := [3]float64{}
+	for i := range light {
+		core[i] = light[i] + (255-light[i])*0.6
+	}
+	for idx, d := range distances {
+		if d > reach*reach {
+			continue
+		}
+		d = math.Sqrt(d)
+		halo := 0.7 * math.Exp(-(d/radius)*(d/radius))
+		coverage := math.Min(math.Max(half+0.5-d, 0), 1)
+		for i := range light {
+			v := c.pix[idx][i] + light[i]*halo
+			c.pix[idx][i] = v + (core[i]-v)*coverage
+		}
+	}
+}
+
+func squaredDistanceToSegment(p, a, b svg.Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Min(math.Max(((p.X-a.X)*dx+(p.Y-a.Y)*dy)/l, 0), 1)
+	}
+	x, y := p.X-(a.X+t*dx), p.Y-(a.Y+t*dy)
+	return x*x + y*y
+}
+
+// scaleBar draws a bar of a round length in mm, at most maxWidth pixels wide, starting at x, y.
+func (c *canvas) scaleBar(x, y, pxPerMm, maxWidth float64) {
+	maxLength := maxWidth / pxPerMm
+	magnitude := math.Pow(10, math.Floor(math.Log10(maxLength)))
+	length := magnitude
+	for _, m := range []float64{2, 5} {
+		if magnitude*m <= maxLength {
+			length = magnitude * m
+		}
+	}
+	white := color.RGBA{R: 230, G: 230, B: 230, A: 255}
+	barWidth := int(math.Round(length * pxPerMm))
+	x0, y0 := int(x), int(y)
+	for dx := 0; dx <= barWidth; dx++ {
+		c.set(x0+dx, y0, white)
+		c.set(x0+dx, y0+1, white)
+	}
+	for dy := -4; dy <= 5; dy++ {
+		c.set(x0, y0+dy, white)
+		c.set(x0+barWidth, y0+dy, white)
+	}
+	c.text(x0+barWidth+10, y0-5, strconv.FormatFloat(length, 'f', -1, 64)+" mm", 2, white)
+}
+
+func (c *canvas) image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, c.width, c.height))
+	for idx, p := range c.pix {
+		img.Pix[idx*4] = clamp(p[0])
+		img.Pix[idx*4+1] = clamp(p[1])
+		img.Pix[idx*4+2] = clamp(p[2])
+		img.Pix[idx*4+3] = 255
+	}
+	return img
+}
+
+func clamp(v float64) uint8 {
+	return uint8(math.Round(math.Min(math.Max(v, 0), 255)))
+}
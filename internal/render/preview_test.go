@@ -0,0 +1,81 @@
+package render
+
+import (
+	"image/color"
+	"testing"
+
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Preview(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	design := Design{
+		Layers: []Layer{{
+			Polylines: []svg.Polyline{{{X: 0, Y: 50}, {X: 200, Y: 50}}},
+			Color:     red,
+			Thickness: 4,
+		}},
+		Backing:      []svg.Polyline{{{X: 0, Y: 0}, {X: 200, Y: 0}, {X: 200, Y: 100}, {X: 0, Y: 100}, {X: 0, Y: 0}}},
+		BackingColor: color.RGBA{B: 80, A: 255},
+		UnitsPerMm:   1,
+	}
+
+	img, err := Preview(design, 400)
+	require.NoError(t, err)
+	// the 200x100 design is scaled to 360 pixels between the 20 pixels margins.
+	assert.Equal(t, 400, img.Bounds().Dx())
+	assert.Equal(t, 20+180+20+40, img.Bounds().Dy())
+
+	assert.Equal(t, Dark, img.RGBAAt(5, 5), "background")
+	assert.Equal(t, color.RGBA{B: 80, A: 255}, img.RGBAAt(50, 30), "backing away from the stroke")
+	core := img.RGBAAt(200, 110)
+	assert.Equal(t, uint8(255), core.R, "stroke")
+	assert.Greater(t, core.G, uint8(100), "the core of the stroke is whiter than its color")
+	halo := img.RGBAAt(200, 118)
+	assert.Greater(t, halo.R, uint8(80), "halo")
+
+	_, err = Preview(Design{}, 400)
+	assert.ErrorIs(t, err, ErrEmptyDesign)
+}
+
+func Test_Preview_tallDesign(t *testing.T) {
+	design := Design{Layers: []Layer{{
+		Polylines: []svg.Polyline{{{X: 0, Y: 0}, {X: 1, Y: 100000}}},
+		Color:     color.RGBA{R: 255, A: 255},
+		Thickness: 4,
+	}}}
+
+	img, err := Preview(design, 400)
+	require.NoError(t, err)
+	// the design is fitted on its height, the image is not taller than wide.
+	assert.Equal(t, 400, img.Bounds().Dx())
+	assert.Equal(t, 20+360+20+40, img.Bounds().Dy())
+}
+
+func Test_ParseHexColor(t *testing.T) {
+	tests := map[string]struct {
+		color   string
+		want    color.RGBA
+		wantErr bool
+	}{
+		"lower case": {color: "#ff5fa2", want: color.RGBA{R: 255, G: 95, B: 162, A: 255}},
+		"upper case": {color: "#00FF10", want: color.RGBA{G: 255, B: 16, A: 255}},
+		"no hash":    {color: "ff5fa2", wantErr: true},
+		"short":      {color: "#fff", wantErr: true},
+		"not hex":    {color: "#gggggg", wantErr: true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseHexColor(tt.color)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"theo303/neon-pricer/internal/svg"
+)
+
+const (
+	backingColor = "#f2f2f2"
+	entryColor   = "#000000"
+)
+
+// WiredRun is a run of strokes fed by one LED strip.
+type WiredRun struct {
+	Polylines []svg.Polyline
+	Feed, End svg.Point
+}
+
+// WiredGroup is a group of a design with its runs in the order they are wired.
+type WiredGroup struct {
+	ID             string
+	Runs           []WiredRun
+	CableLengthMm  float64
+	JumperLengthMm float64
+}
+
+// WiringDiagram holds the wiring of a design to the controller at the power entry of its backing.
+type WiringDiagram struct {
+	Backing svg.Bounds
+	Entry   svg.Point
+	Groups  []WiredGroup
+}
+
+// Wiring writes the wiring diagram as a svg image: the backing, the runs of each group with their
+// numbered feed points, the jumpers between runs dotted, the cables to the power entry dashed
+// and, under the backing, the lengths of cable of each group.
+func Wiring(w io.Writer, d WiringDiagram) error {
+	lo, hi := d.Backing.Min(), d.Backing.Max()
+	size := math.Max(math.Hypot(d.Backing.Width(), d.Backing.Height()), 1)
+	stroke, marker, font := size/400, size/150, size/60
+	margin := size / 20
+	legend := font * 1.5 * float64(len(d.Groups)+1)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="%s %s %s %s" font-family="sans-serif">`+"\n",
+		num(lo.X-margin), num(lo.Y-margin), num(d.Backing.Width()+2*margin), num(d.Backing.Height()+2*margin+legend))
+	fmt.Fprintf(&buf, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s" stroke="#888888" stroke-width="%s"/>`+"\n",
+		num(lo.X), num(lo.Y), num(d.Backing.Width()), num(d.Backing.Height()), backingColor, num(stroke))
+
+	for i, g := range d.Groups {
+		color := Palette[i%len(Palette)]
+		fmt.Fprintf(&buf, `<g id="wiring-%s" fill="none" stroke="%s">`+"\n", escape(g.ID), color)
+		for n, run := range g.Runs {
+			for _, pl := range run.Polylines {
+				if len(pl) == 0 {
+					continue
+				}
+				points := make([]string, len(pl))
+				for j, p := range pl {
+					points[j] = num(p.X) + "," + num(p.Y)
+				}
+				fmt.Fprintf(&buf, `<polyline points="%s" stroke-width="%s" stroke-opacity="0.5"/>`+"\n",
+					strings.Join(points, " "), num(stroke*3))
+			}
+			if n > 0 {
+				prev := g.Runs[n-1].End
+				fmt.Fprintf(&buf, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke-width="%s" stroke-dasharray="%s %s"/>`+"\n",
+					num(prev.X), num(prev.Y), num(run.Feed.X), num(run.Feed.Y), num(stroke), num(stroke), num(stroke*2))
+			}
+			fmt.Fprintf(&buf, `<path d="M%s %s H%s V%s" stroke-width="%s" stroke-dasharray="%s %s"/>`+"\n",
+				num(run.Feed.X), num(run.Feed.Y), num(d.Entry.X), num(d.Entry.Y), num(stroke), num(marker), num(marker/2))
+			fmt.Fprintf(&buf, `<circle cx="%s" cy="%s" r="%s" fill="%s" stroke="none"/>`+"\n",
+				num(run.Feed.X), num(run.Feed.Y), num(marker), color)
+			fmt.Fprintf(&buf, `<text x="%s" y="%s" font-size="%s" fill="%s" stroke="none">%s %d</text>`+"\n",
+				num(run.Feed.X+marker*1.5), num(run.Feed.Y-marker*1.5), num(font*0.7), color, escape(g.ID), n+1)
+		}
+		fmt.Fprintf(&buf, `<text x="%s" y="%s" font-size="%s" fill="%s" stroke="none">%s: %d feed points, %.0f mm of cable, %.0f mm of jumpers</text>`+"\n",
+			num(lo.X), num(hi.Y+margin+font*1.5*float64(i+1)), num(font), color, escape(g.ID), len(g.Runs), g.CableLengthMm, g.JumperLengthMm)
+		buf.WriteString("</g>\n")
+	}
+
+	fmt.Fprintf(&buf, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"><title>power entry</title></rect>`+"\n",
+		num(d.Entry.X-marker), num(d.Entry.Y-marker), num(marker*2), num(marker*2), entryColor)
+	fmt.Fprintf(&buf, `<text x="%s" y="%s" font-size="%s" fill="%s">power entry</text>`+"\n",
+		num(d.Entry.X+marker*1.5), num(d.Entry.Y+marker*3), num(font*0.7), entryColor)
+	buf.WriteString("</svg>\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
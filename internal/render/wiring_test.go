@@ -0,0 +1,40 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Wiring(t *testing.T) {
+	backing := svg.Polyline{{X: 0, Y: 0}, {X: 100, Y: 50}}.Bounds()
+	var buf bytes.Buffer
+	err := Wiring(&buf, WiringDiagram{
+		Backing: backing,
+		Entry:   svg.Point{X: 50, Y: 50},
+		Groups: []WiredGroup{{
+			ID: "6MM",
+			Runs: []WiredRun{
+				{Polylines: []svg.Polyline{{{X: 10, Y: 10}, {X: 40, Y: 10}}}, Feed: svg.Point{X: 10, Y: 10}, End: svg.Point{X: 40, Y: 10}},
+				{Polylines: []svg.Polyline{{{X: 60, Y: 10}, {X: 90, Y: 10}}}, Feed: svg.Point{X: 60, Y: 10}, End: svg.Point{X: 90, Y: 10}},
+			},
+			CableLengthMm:  130,
+			JumperLengthMm: 20,
+		}},
+	})
+	require.NoError(t, err)
+
+	got := buf.String()
+	assert.True(t, strings.HasPrefix(got, `<svg xmlns="http://www.w3.org/2000/svg"`))
+	assert.Contains(t, got, `<polyline points="10,10 40,10"`)
+	assert.Contains(t, got, `<line x1="40" y1="10" x2="60" y2="10"`, "runs are chained by jumpers")
+	assert.Contains(t, got, `<path d="M60 10 H50 V50"`, "cables go to the power entry")
+	assert.Contains(t, got, "6MM 2</text>")
+	assert.Contains(t, got, "6MM: 2 feed points, 130 mm of cable, 20 mm of jumpers")
+	assert.Contains(t, got, "<title>power entry</title>")
+}
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"theo303/neon-pricer/internal/domain"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// openTimeout is how long to wait for another process to release the database.
+const openTimeout = 5 * time.Second
+
+var (
+	quotesBucket = []byte("quotes")
+	svgsBucket   = []byte("svgs")
+)
+
+// ErrQuoteNotFound is returned when no quote matches the requested ID.
+var ErrQuoteNotFound = errors.New("quote not found")
+
+// QuoteStore persists quotes in a bbolt database file.
+// The database is opened for each operation so the file is not locked while idle,
+// which lets the CLI read quotes while the server is running.
+type QuoteStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewQuoteStore(path string) *QuoteStore {
+	return &QuoteStore{path: path}
+}
+
+// Filter selects quotes when listing them, zero fields are ignored.
+type Filter struct {
+	// Search is matched, case insensitively, against the file name and the ID.
+	Search string
+	// Hash is a prefix of the hash of the svg file.
+	Hash  string
+	Since time.Time
+	Until time.Time
+	// Limit is the maximum number of quotes returned.
+	Limit int
+}
+
+func (f Filter) match(q domain.Quote) bool {
+	search := strings.ToLower(f.Search)
+	switch {
+	case search != "" &&
+		!strings.Contains(strings.ToLower(q.FileName), search) &&
+		!strings.Contains(strings.ToLower(q.ID), search):
+		return false
+	case f.Hash != "" && !strings.HasPrefix(q.Hash, strings.ToLower(f.Hash)):
+		return false
+	case !f.Since.IsZero() && q.CreatedAt.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && q.CreatedAt.After(f.Until):
+		return false
+	}
+	return true
+}
+
+// Save stores the quote and its svg file, giving the quote an ID if it has none.
+func (s *QuoteStore) Save(quote *domain.Quote) error {
+	if quote.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return fmt.Errorf("generating id: %w", err)
+		}
+		quote.ID = id
+	}
+	value, err := json.Marshal(quote)
+	if err != nil {
+		return fmt.Errorf("encoding quote: %w", err)
+	}
+
+	return s.update(func(tx *bolt.Tx) error {
+		quotes, err := tx.CreateBucketIfNotExists(quotesBucket)
+		if err != nil {
+			return err
+		}
+		svgs, err := tx.CreateBucketIfNotExists(svgsBucket)
+		if err != nil {
+			return err
+		}
+		if err := quotes.Put([]byte(quote.ID), value); err != nil {
+			return err
+		}
+		return svgs.Put([]byte(quote.ID), quote.SVG)
+	})
+}
+
+// Get returns the quote identified by id, along with its svg file.
+func (s *QuoteStore) Get(id string) (domain.Quote, error) {
+	var quote domain.Quote
+	err := s.view(func(tx *bolt.Tx) error {
+		quotes := tx.Bucket(quotesBucket)
+		if quotes == nil {
+			return ErrQuoteNotFound
+		}
+		value := quotes.Get([]byte(id))
+		if value == nil {
+			return ErrQuoteNotFound
+		}
+		if err := json.Unmarshal(value, &quote); err != nil {
+			return fmt.Errorf("decoding quote %s: %w", id, err)
+		}
+		if svgs := tx.Bucket(svgsBucket); svgs != nil {
+			quote.SVG = slices.Clone(svgs.Get([]byte(id)))
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return domain.Quote{}, ErrQuoteNotFound
+	}
+	return quote, err
+}
+
+// List returns the quotes matching the filter, newest first, without their svg file.
+func (s *QuoteStore) List(filter Filter) ([]domain.Quote, error) {
+	var list []domain.Quote
+	err := s.view(func(tx *bolt.Tx) error {
+		quotes := tx.Bucket(quotesBucket)
+		if quotes == nil {
+			return nil
+		}
+		return quotes.ForEach(func(k, v []byte) error {
+			var quote domain.Quote
+			if err := json.Unmarshal(v, &quote); err != nil {
+				return fmt.Errorf("decoding quote %s: %w", k, err)
+			}
+			if filter.match(quote) {
+				list = append(list, quote)
+			}
+			return nil
+		})
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	slices.SortFunc(list, func(a, b domain.Quote) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	if filter.Limit > 0 && len(list) > filter.Limit {
+		list = list[:filter.Limit]
+	}
+	return list, nil
+}
+
+func (s *QuoteStore) update(fn func(tx *bolt.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	db, err := bolt.Open(s.path, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return fmt.Errorf("opening quote database %s: %w", s.path, err)
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+// view runs fn in a read-only transaction, it returns an error matching os.ErrNotExist
+// if the database has not been created yet.
+func (s *QuoteStore) view(fn func(tx *bolt.Tx) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(s.path); err != nil {
+		return err
+	}
+	db, err := bolt.Open(s.path, 0o600, &bolt.Options{Timeout: openTimeout, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("opening quote database %s: %w", s.path, err)
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+// newID returns a random identifier, hard to guess so that quote URLs can be shared.
+func newID() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToLower(base32.StdEncoding.EncodeToString(b)), nil
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"theo303/neon-pricer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_QuoteStore(t *testing.T) {
+	store := NewQuoteStore(filepath.Join(t.TempDir(), "quotes.db"))
+
+	list, err := store.List(Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+	_, err = store.Get("missing")
+	assert.ErrorIs(t, err, ErrQuoteNotFound)
+
+	now := time.Now().Truncate(time.Second)
+	older := domain.Quote{
+		CreatedAt: now.Add(-time.Hour),
+		FileName:  "albertine.svg",
+		Hash:      "abcdef",
+		Sizes:     map[string]domain.Size{"8MM": {Length: 1200}},
+		Prices:    domain.Price{"8MM": {SiliconePrice: 1.02}},
+		SVG:       []byte("<svg></svg>"),
+	}
+	newer := domain.Quote{
+		CreatedAt: now,
+		FileName:  "ecto.svg",
+		Hash:      "123456",
+	}
+	require.NoError(t, store.Save(&older))
+	require.NoError(t, store.Save(&newer))
+	assert.NotEmpty(t, older.ID)
+	assert.NotEqual(t, older.ID, newer.ID)
+
+	got, err := store.Get(older.ID)
+	require.NoError(t, err)
+	assert.Equal(t, older.Sizes, got.Sizes)
+	assert.Equal(t, older.Prices, got.Prices)
+	assert.Equal(t, older.SVG, got.SVG)
+
+	tests := map[string]struct {
+		filter Filter
+		want   []string
+	}{
+		"all, newest first": {
+			want: []string{newer.ID, older.ID},
+		},
+		"search file name": {
+			filter: Filter{Search: "ALBERT"},
+			want:   []string{older.ID},
+		},
+		"hash prefix": {
+			filter: Filter{Hash: "123"},
+			want:   []string{newer.ID},
+		},
+		"since": {
+			filter: Filter{Since: now.Add(-time.Minute)},
+			want:   []string{newer.ID},
+		},
+		"limit": {
+			filter: Filter{Limit: 1},
+			want:   []string{newer.ID},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			list, err := store.List(tt.filter)
+			require.NoError(t, err)
+			var ids []string
+			for _, q := range list {
+				ids = append(ids, q.ID)
+				assert.Nil(t, q.SVG)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package svg
+
+import "math"
+
+// Bend is a vertex of a polyline where the stroke turns.
+type Bend struct {
+	Point Point
+	// Angle is the turning angle at the vertex in radians, between 0 and π.
+	Angle float64
+	// Radius is the radius of the curve through the vertex, 0 at a corner.
+	Radius float64
+}
+
+// Corner reports whether the stroke turns at once at the bend.
+func (b Bend) Corner() bool {
+	return b.Radius == 0
+}
+
+// Bends returns the vertices of the polyline where it turns. A vertex turning by more than
+// cornerAngle radians is a corner. The radius of the others is fitted over the stroke around them,
+// from the middle of their segments on to at least span long without crossing a corner, so that the
+// small kinks of flattened curves are measured over the length the stroke is bent on.
+// The radius is infinite where the stroke turns back as much as it turns within the span.
+func (pl Polyline) Bends(cornerAngle, span float64) []Bend {
+	points := make([]Point, 0, len(pl))
+	for i, p := range pl {
+		if i == 0 || p != pl[i-1] {
+			points = append(points, p)
+		}
+	}
+	closed := pl.Closed()
+	if closed {
+		points = points[:len(points)-1]
+	}
+	n := len(points)
+	if n < 3 {
+		return nil
+	}
+
+	// turns are the signed turning angles at the vertices, lengths the lengths of the stroke
+	// from the middle of the segment before them to the middle of the segment after them.
+	turns := make([]float64, n)
+	lengths := make([]float64, n)
+	for i, p := range points {
+		if !closed && (i == 0 || i == n-1) {
+			continue
+		}
+		prev, next := points[(i+n-1)%n], points[(i+1)%n]
+		ux, uy := p.X-prev.X, p.Y-prev.Y
+		vx, vy := next.X-p.X, next.Y-p.Y
+		turns[i] = math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+		lengths[i] = (math.Hypot(ux, uy) + math.Hypot(vx, vy)) / 2
+	}
+	// curve reports whether the stroke goes on bending at the vertex j.
+	curve := func(j int) bool {
+		if !closed && (j <= 0 || j >= n-1) {
+			return false
+		}
+		return math.Abs(turns[(j+n)%n]) <= cornerAngle
+	}
+
+	var bends []Bend
+	for i, p := range points {
+		turn := math.Abs(turns[i])
+		if turn < 1e-9 {
+			continue
+		}
+		bend := Bend{Point: p, Angle: turn}
+		if turn <= cornerAngle {
+			length, sum := lengths[i], turns[i]
+			for first, last := i, i; length < span && last-first+1 < n; {
+				grown := false
+				if curve(first - 1) {
+					first--
+					length += lengths[(first+n)%n]
+					sum += turns[(first+n)%n]
+					grown = true
+				}
+				if curve(last+1) && last-first+1 < n {
+					last++
+					length += lengths[last%n]
+					sum += turns[last%n]
+					grown = true
+				}
+				if !grown {
+					break
+				}
+			}
+			bend.Radius = math.Inf(1)
+			if math.Abs(sum) >= 1e-9 {
+				bend.Radius = length / math.Abs(sum)
+			}
+		}
+		bends = append(bends, bend)
+	}
+	return bends
+}
+
+// SegmentsDistance returns the distance between the segments [a1, a2] and [b1, b2],
+// and the middle of their closest points.
+func SegmentsDistance(a1, a2, b1, b2 Point) (float64, Point) {
+	if crossing, ok := segmentsIntersection(a1, a2, b1, b2); ok {
+		return 0, crossing
+	}
+	best := math.Inf(1)
+	var mid Point
+	for _, c := range [][3]Point{{a1, b1, b2}, {a2, b1, b2}, {b1, a1, a2}, {b2, a1, a2}} {
+		closest := closestOnSegment(c[0], c[1], c[2])
+		if d := math.Hypot(c[0].X-closest.X, c[0].Y-closest.Y); d < best {
+			best = d
+			mid = Point{X: (c[0].X + closest.X) / 2, Y: (c[0].Y + closest.Y) / 2}
+		}
+	}
+	return best, mid
+}
+
+// closestOnSegment returns the point of the segment [a, b] closest to p.
+func closestOnSegment(p, a, b Point) Point {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lengthSq := dx*dx + dy*dy
+	if lengthSq == 0 {
+		return a
+	}
+	t := max(0, min(1, ((p.X-a.X)*dx+(p.Y-a.Y)*dy)/lengthSq))
+	return Point{X: a.X + t*dx, Y: a.Y + t*dy}
+}
+
+// segmentsIntersection returns the point where the segments [a1, a2] and [b1, b2] cross, if they do.
+func segmentsIntersection(a1, a2, b1, b2 Point) (Point, bool) {
+	rx, ry := a2.X-a1.X, a2.Y-a1.Y
+	sx, sy := b2.X-b1.X, b2.Y-b1.Y
+	denom := rx*sy - ry*sx
+	if denom == 0 {
+		return Point{}, false
+	}
+	t := ((b1.X-a1.X)*sy - (b1.Y-a1.Y)*sx) / denom
+	u := ((b1.X-a1.X)*ry - (b1.Y-a1.Y)*rx) / denom
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return Point{}, false
+	}
+	return Point{X: a1.X + t*rx, Y: a1.Y + t*ry}, true
+}
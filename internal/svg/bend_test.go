@@ -0,0 +1,150 @@
+package svg
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Polyline_Bends(t *testing.T) {
+	const cornerAngle = math.Pi / 9
+	tests := map[string]struct {
+		form        Form
+		wantBends   int
+		wantCorners int
+		wantRadius  float64
+	}{
+		"line": {
+			form: Line{p1: point{0, 0}, p2: point{10, 0}},
+		},
+		"rectangle": {
+			form:        Rectangle{point: point{0, 0}, width: 10, height: 5},
+			wantBends:   4,
+			wantCorners: 4,
+		},
+		"circle": {
+			form:       Circle{point: point{0, 0}, r: 20},
+			wantBends:  360,
+			wantRadius: 20,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			polylines, err := tt.form.Polylines()
+			require.NoError(t, err)
+			require.Len(t, polylines, 1)
+			bends := polylines[0].Bends(cornerAngle, 0)
+			assert.Len(t, bends, tt.wantBends)
+			var corners int
+			for _, b := range bends {
+				if b.Corner() {
+					corners++
+					assert.InDelta(t, math.Pi/2, b.Angle, 1e-9)
+					continue
+				}
+				assert.InDelta(t, tt.wantRadius, b.Radius, 0.01)
+			}
+			assert.Equal(t, tt.wantCorners, corners)
+		})
+	}
+}
+
+func Test_Path_Bends(t *testing.T) {
+	path, err := parsePathCommand("M0,0h100a20,20,0,0,1,0,40h-100", -1)
+	require.NoError(t, err)
+	polylines, err := path.Polylines()
+	require.NoError(t, err)
+	require.Len(t, polylines, 1)
+
+	// the joints of the arc and the lines turn by half a flattening step over a whole line.
+	var onArc int
+	for _, b := range polylines[0].Bends(math.Pi/9, 0) {
+		assert.False(t, b.Corner())
+		assert.GreaterOrEqual(t, b.Point.X, 100.0)
+		if b.Radius < 100 {
+			assert.InDelta(t, 20, b.Radius, 0.5)
+			onArc++
+		}
+	}
+	assert.Equal(t, 179, onArc)
+}
+
+func Test_Polyline_Bends_span(t *testing.T) {
+	// ten unit segments on each side of a kink turning by 30 degrees.
+	var kink Polyline
+	for i := 0; i <= 10; i++ {
+		kink = append(kink, Point{X: float64(i)})
+	}
+	for i := 1; i <= 10; i++ {
+		kink = append(kink, Point{X: 10 + float64(i)*math.Cos(math.Pi/6), Y: float64(i) * math.Sin(math.Pi/6)})
+	}
+	circle, err := Circle{point: point{0, 0}, r: 20}.Polylines()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		pl         Polyline
+		span       float64
+		wantRadius float64
+	}{
+		"kink alone": {
+			pl:         kink,
+			wantRadius: 1 / (math.Pi / 6),
+		},
+		"kink over the span": {
+			pl:         kink,
+			span:       10,
+			wantRadius: 11 / (math.Pi / 6),
+		},
+		"kink over the whole stroke": {
+			pl:         kink,
+			span:       100,
+			wantRadius: 19 / (math.Pi / 6),
+		},
+		"circle over the span": {
+			pl:         circle[0],
+			span:       10,
+			wantRadius: 20,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tightest := math.Inf(1)
+			for _, b := range tt.pl.Bends(math.Pi/4, tt.span) {
+				assert.False(t, b.Corner())
+				tightest = min(tightest, b.Radius)
+			}
+			assert.InDelta(t, tt.wantRadius, tightest, 0.01)
+		})
+	}
+}
+
+func Test_SegmentsDistance(t *testing.T) {
+	tests := map[string]struct {
+		a1, a2, b1, b2 Point
+		want           float64
+		wantPoint      Point
+	}{
+		"crossing": {
+			a1: Point{0, 0}, a2: Point{10, 10}, b1: Point{0, 10}, b2: Point{10, 0},
+			want: 0, wantPoint: Point{5, 5},
+		},
+		"parallel": {
+			a1: Point{0, 0}, a2: Point{10, 0}, b1: Point{5, 4}, b2: Point{20, 4},
+			want: 4, wantPoint: Point{10, 2},
+		},
+		"end to end": {
+			a1: Point{0, 0}, a2: Point{10, 0}, b1: Point{13, 4}, b2: Point{20, 4},
+			want: 5, wantPoint: Point{11.5, 2},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, point := SegmentsDistance(tt.a1, tt.a2, tt.b1, tt.b2)
+			assert.InDelta(t, tt.want, got, 1e-9)
+			assert.InDelta(t, tt.wantPoint.X, point.X, 1e-9)
+			assert.InDelta(t, tt.wantPoint.Y, point.Y, 1e-9)
+		})
+	}
+}
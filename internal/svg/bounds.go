@@ -30,3 +30,13 @@ func (b Bounds) expandPoint(p point) Bounds {
 		maxY: max(b.maxY, p.y),
 	}
 }
+
+// Min returns the top left corner of the bounds.
+func (b Bounds) Min() Point {
+	return Point{X: b.minX, Y: b.minY}
+}
+
+// Max returns the bottom right corner of the bounds.
+func (b Bounds) Max() Point {
+	return Point{X: b.maxX, Y: b.maxY}
+}
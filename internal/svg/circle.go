@@ -13,6 +13,11 @@ type Circle struct {
 	r float64
 }
 
+// NewCircle returns the circle of radius r around center.
+func NewCircle(center Point, r float64) Circle {
+	return Circle{point: point{x: center.X, y: center.Y}, r: r}
+}
+
 func (c Circle) Length() (float64, error) {
 	return 2 * math.Pi * c.r, nil
 }
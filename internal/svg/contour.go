@@ -0,0 +1,284 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+)
+
+// SegmentKind is the kind of curve of a segment.
+type SegmentKind string
+
+const (
+	SegmentLine   SegmentKind = "line"
+	SegmentArc    SegmentKind = "arc"
+	SegmentBezier SegmentKind = "bezier"
+)
+
+// maxBezierDepth bounds the number of times a bezier curve is split when it is flattened.
+const maxBezierDepth = 16
+
+// Segment is a line, an elliptical arc or a cubic bezier curve going from Start to End.
+type Segment struct {
+	Kind       SegmentKind
+	Start, End Point
+	// Controls are the control points of bezier curves.
+	Controls [2]Point
+	// Center, RX and RY are the center and the radii of the ellipse of arcs, turned by Rotation radians.
+	// The arc goes from the angle StartAngle of the ellipse to StartAngle+Sweep, positive sweeps going
+	// from the x-axis towards the y-axis.
+	Center                      Point
+	RX, RY                      float64
+	Rotation, StartAngle, Sweep float64
+}
+
+// Circular reports whether the segment is an arc of a circle.
+func (s Segment) Circular() bool {
+	return s.Kind == SegmentArc && math.Abs(s.RX-s.RY) <= 1e-9*math.Max(s.RX, s.RY)
+}
+
+// ArcPoint returns the point of the ellipse of the arc at angle t.
+func (s Segment) ArcPoint(t float64) Point {
+	cos, sin := math.Cos(s.Rotation), math.Sin(s.Rotation)
+	x, y := s.RX*math.Cos(t), s.RY*math.Sin(t)
+	return Point{X: cos*x - sin*y + s.Center.X, Y: sin*x + cos*y + s.Center.Y}
+}
+
+// Contour is a stroke made of consecutive segments, each one starting at the end of the previous one.
+type Contour []Segment
+
+// Closed reports whether the contour ends where it starts.
+func (c Contour) Closed() bool {
+	return len(c) > 0 && c[0].Start == c[len(c)-1].End
+}
+
+// LineContour returns the contour made of the segments of the polyline.
+func LineContour(pl Polyline) Contour {
+	c := make(Contour, 0, len(pl))
+	for i := 1; i < len(pl); i++ {
+		c = append(c, Segment{Kind: SegmentLine, Start: pl[i-1], End: pl[i]})
+	}
+	return c
+}
+
+// Transform returns the contour with its points p moved to (p - origin) * scale, scale being positive.
+func (c Contour) Transform(origin Point, scale float64) Contour {
+	move := func(p Point) Point {
+		return Point{X: (p.X - origin.X) * scale, Y: (p.Y - origin.Y) * scale}
+	}
+	moved := make(Contour, len(c))
+	for i, s := range c {
+		s.Start, s.End = move(s.Start), move(s.End)
+		switch s.Kind {
+		case SegmentArc:
+			s.Center = move(s.Center)
+			s.RX, s.RY = s.RX*scale, s.RY*scale
+		case SegmentBezier:
+			s.Controls = [2]Point{move(s.Controls[0]), move(s.Controls[1])}
+		}
+		moved[i] = s
+	}
+	return moved
+}
+
+// Flatten returns the polyline of the contour, its curves being split into lines
+// at most tolerance away from them. The tolerance must be positive.
+func (c Contour) Flatten(tolerance float64) Polyline {
+	if len(c) == 0 {
+		return nil
+	}
+	pl := Polyline{c[0].Start}
+	for _, s := range c {
+		pl = append(pl, s.flatten(tolerance)...)
+	}
+	return pl
+}
+
+// flatten returns the points of the segment after its start.
+func (s Segment) flatten(tolerance float64) []Point {
+	switch s.Kind {
+	case SegmentArc:
+		r := math.Max(s.RX, s.RY)
+		// the sagitta of the chords of the steps is at most the tolerance.
+		step := math.Pi / 2
+		if tolerance < r {
+			step = math.Min(step, 2*math.Acos(1-tolerance/r))
+		}
+		n := int(math.Ceil(math.Abs(s.Sweep) / step))
+		points := make([]Point, 0, n)
+		for i := 1; i < n; i++ {
+			points = append(points, s.ArcPoint(s.StartAngle+s.Sweep*float64(i)/float64(n)))
+		}
+		return append(points, s.End)
+	case SegmentBezier:
+		return flattenCubic(s.Start, s.Controls[0], s.Controls[1], s.End, tolerance, 0)
+	}
+	return []Point{s.End}
+}
+
+// flattenCubic returns the points of the cubic bezier curve after its start, splitting it in halves
+// until its control points are closer to its chord than the tolerance.
+func flattenCubic(p0, p1, p2, p3 Point, tolerance float64, depth int) []Point {
+	d1, _ := SegmentsDistance(p1, p1, p0, p3)
+	d2, _ := SegmentsDistance(p2, p2, p0, p3)
+	if math.Max(d1, d2) <= tolerance || depth >= maxBezierDepth {
+		return []Point{p3}
+	}
+	mid := func(a, b Point) Point { return Point{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2} }
+	p01, p12, p23 := mid(p0, p1), mid(p1, p2), mid(p2, p3)
+	p012, p123 := mid(p01, p12), mid(p12, p23)
+	m := mid(p012, p123)
+	return append(flattenCubic(p0, p01, p012, m, tolerance, depth+1), flattenCubic(m, p123, p23, p3, tolerance, depth+1)...)
+}
+
+// Contours returns the strokes the form is made of, keeping its arcs and curves.
+func Contours(f Form) ([]Contour, error) {
+	switch f := f.(type) {
+	case Line:
+		return []Contour{{{Kind: SegmentLine, Start: f.p1.export(), End: f.p2.export()}}}, nil
+	case Circle:
+		start := Point{X: f.x + f.r, Y: f.y}
+		return []Contour{{{
+			Kind: SegmentArc, Start: start, End: start,
+			Center: f.export(), RX: f.r, RY: f.r, Sweep: 2 * math.Pi,
+		}}}, nil
+	case Path:
+		return f.contours()
+	}
+	polylines, err := f.Polylines()
+	if err != nil {
+		return nil, err
+	}
+	contours := make([]Contour, 0, len(polylines))
+	for _, pl := range polylines {
+		contours = append(contours, LineContour(pl))
+	}
+	return contours, nil
+}
+
+// contours returns the strokes of the path, quadratic bezier curves being raised to cubic ones.
+func (p Path) contours() ([]Contour, error) {
+	var contours []Contour
+	var current Contour
+	var firstPos, lastPos, lastCtrl point
+
+	flush := func() {
+		if len(current) > 0 {
+			contours = append(contours, current)
+		}
+		current = nil
+	}
+	add := func(s Segment) {
+		s.Start = lastPos.export()
+		current = append(current, s)
+		lastPos = point{x: s.End.X, y: s.End.Y}
+	}
+	lineTo := func(pt point) {
+		add(Segment{Kind: SegmentLine, End: pt.export()})
+	}
+	cubicTo := func(c1, c2, end point) {
+		add(Segment{Kind: SegmentBezier, Controls: [2]Point{c1.export(), c2.export()}, End: end.export()})
+	}
+	// quadTo raises the quadratic curve to the cubic one with the same points.
+	quadTo := func(ctrl, end point) {
+		cubicTo(
+			point{x: lastPos.x + 2*(ctrl.x-lastPos.x)/3, y: lastPos.y + 2*(ctrl.y-lastPos.y)/3},
+			point{x: end.x + 2*(ctrl.x-end.x)/3, y: end.y + 2*(ctrl.y-end.y)/3},
+			end,
+		)
+	}
+
+	for cmd := &p; cmd != nil; cmd = cmd.Next {
+		if !cmd.checkNumberOfParams() {
+			return nil, fmt.Errorf("invalid number of parameters (%d) for command %c", len(cmd.Parameters), cmd.Command)
+		}
+		params := cmd.Parameters
+		// origin is added to the coordinates of relative commands.
+		var origin point
+		if cmd.Command >= 'a' && cmd.Command <= 'z' {
+			origin = lastPos
+		}
+		switch cmd.Command {
+		case 'M', 'm':
+			flush()
+			firstPos = point{origin.x + params[0], origin.y + params[1]}
+			lastPos = firstPos
+		case 'H':
+			lineTo(point{params[0], lastPos.y})
+		case 'h':
+			lineTo(point{lastPos.x + params[0], lastPos.y})
+		case 'V':
+			lineTo(point{lastPos.x, params[0]})
+		case 'v':
+			lineTo(point{lastPos.x, lastPos.y + params[0]})
+		case 'L', 'l':
+			for i := 0; i < len(params); i += 2 {
+				if cmd.Command == 'l' {
+					origin = lastPos
+				}
+				lineTo(point{origin.x + params[i], origin.y + params[i+1]})
+			}
+		case 'C', 'c':
+			for i := 0; i < len(params); i += 6 {
+				if cmd.Command == 'c' {
+					origin = lastPos
+				}
+				c2 := point{x: origin.x + params[i+2], y: origin.y + params[i+3]}
+				cubicTo(point{x: origin.x + params[i], y: origin.y + params[i+1]}, c2,
+					point{x: origin.x + params[i+4], y: origin.y + params[i+5]})
+				lastCtrl = c2
+			}
+		case 'S', 's':
+			for i := 0; i < len(params); i += 4 {
+				if cmd.Command == 's' {
+					origin = lastPos
+				}
+				c2 := point{x: origin.x + params[i], y: origin.y + params[i+1]}
+				cubicTo(reflectPoint(lastCtrl, lastPos), c2, point{x: origin.x + params[i+2], y: origin.y + params[i+3]})
+				lastCtrl = c2
+			}
+		case 'Q', 'q':
+			for i := 0; i < len(params); i += 4 {
+				if cmd.Command == 'q' {
+					origin = lastPos
+				}
+				ctrl := point{x: origin.x + params[i], y: origin.y + params[i+1]}
+				quadTo(ctrl, point{x: origin.x + params[i+2], y: origin.y + params[i+3]})
+				lastCtrl = ctrl
+			}
+		case 'T', 't':
+			for i := 0; i < len(params); i += 2 {
+				if cmd.Command == 't' {
+					origin = lastPos
+				}
+				ctrl := reflectPoint(lastCtrl, lastPos)
+				quadTo(ctrl, point{x: origin.x + params[i], y: origin.y + params[i+1]})
+				lastCtrl = ctrl
+			}
+		case 'A', 'a':
+			for i := 0; i < len(params); i += 7 {
+				if cmd.Command == 'a' {
+					origin = lastPos
+				}
+				end := point{origin.x + params[i+5], origin.y + params[i+6]}
+				a, err := arcFromSVGParams(lastPos, end, params[i], params[i+1], params[i+2],
+					params[i+3] == 1, params[i+4] == 1)
+				if err != nil {
+					return nil, fmt.Errorf("building arc: %w", err)
+				}
+				add(Segment{
+					Kind: SegmentArc, End: end.export(),
+					Center: a.center.export(), RX: a.rx, RY: a.ry,
+					Rotation: a.phi, StartAngle: a.startAngle, Sweep: a.sweep(),
+				})
+			}
+		case 'Z', 'z':
+			if lastPos != firstPos {
+				lineTo(firstPos)
+			}
+			flush()
+			lastPos = firstPos
+		}
+	}
+	flush()
+	return contours, nil
+}
@@ -0,0 +1,122 @@
+package svg
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Path_contours(t *testing.T) {
+	tests := map[string]struct {
+		pathString string
+		wantKinds  [][]SegmentKind
+		wantClosed bool
+	}{
+		"lines": {
+			pathString: "M10,10h10v10H10z",
+			wantKinds:  [][]SegmentKind{{SegmentLine, SegmentLine, SegmentLine, SegmentLine}},
+			wantClosed: true,
+		},
+		"two sub paths": {
+			pathString: "M0,0l10,0M20,0l0,10",
+			wantKinds:  [][]SegmentKind{{SegmentLine}, {SegmentLine}},
+		},
+		"curves and arc": {
+			pathString: "M0,0L10,0Q20,0,20,10A10,10,0,0,1,10,20C5,20,0,15,0,10Z",
+			wantKinds:  [][]SegmentKind{{SegmentLine, SegmentBezier, SegmentArc, SegmentBezier, SegmentLine}},
+			wantClosed: true,
+		},
+		"closed on its last point": {
+			pathString: "M10,0A10,10,0,0,1,-10,0A10,10,0,0,1,10,0Z",
+			wantKinds:  [][]SegmentKind{{SegmentArc, SegmentArc}},
+			wantClosed: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, err := parsePathCommand(tt.pathString, -1)
+			require.NoError(t, err)
+			contours, err := Contours(*path)
+			require.NoError(t, err)
+			require.Len(t, contours, len(tt.wantKinds))
+			for i, c := range contours {
+				var kinds []SegmentKind
+				for j, s := range c {
+					kinds = append(kinds, s.Kind)
+					if j > 0 {
+						assert.Equal(t, c[j-1].End, s.Start, "segments follow each other")
+					}
+				}
+				assert.Equal(t, tt.wantKinds[i], kinds)
+			}
+			assert.Equal(t, tt.wantClosed, contours[len(contours)-1].Closed())
+
+			// the contours are flattened into the polylines of the path.
+			polylines, err := path.Polylines()
+			require.NoError(t, err)
+			for i, c := range contours {
+				assert.InDelta(t, polylines[i].Length(), c.Flatten(0.01).Length(), polylines[i].Length()*0.001)
+			}
+		})
+	}
+}
+
+func Test_Contour_Flatten(t *testing.T) {
+	circle, err := Contours(Circle{point: point{x: 50, y: 50}, r: 100})
+	require.NoError(t, err)
+	cubic := Contour{{
+		Kind: SegmentBezier, Start: Point{X: 0, Y: 0}, End: Point{X: 100, Y: 0},
+		Controls: [2]Point{{X: 0, Y: 100}, {X: 100, Y: 100}},
+	}}
+	bezierPoint := func(t float64) Point {
+		u := 1 - t
+		return Point{X: 3*u*t*t*100 + t*t*t*100, Y: 3*u*u*t*100 + 3*u*t*t*100}
+	}
+	tests := map[string]struct {
+		contour Contour
+		// curve returns the point of the contour at t in [0, 1].
+		curve func(t float64) Point
+	}{
+		"circle": {
+			contour: circle[0],
+			curve: func(t float64) Point {
+				return Point{X: 50 + 100*math.Cos(2*math.Pi*t), Y: 50 + 100*math.Sin(2*math.Pi*t)}
+			},
+		},
+		"bezier": {
+			contour: cubic,
+			curve:   bezierPoint,
+		},
+	}
+	for name, tt := range tests {
+		for _, tolerance := range []float64{1, 0.1} {
+			t.Run(name, func(t *testing.T) {
+				pl := tt.contour.Flatten(tolerance)
+				assert.Equal(t, tt.curve(0), pl[0])
+				assert.InDelta(t, tt.curve(1).X, pl[len(pl)-1].X, 1e-9)
+				assert.InDelta(t, tt.curve(1).Y, pl[len(pl)-1].Y, 1e-9)
+				for i := 0; i <= 1000; i++ {
+					p := tt.curve(float64(i) / 1000)
+					distance := math.Inf(1)
+					for j := 1; j < len(pl); j++ {
+						d, _ := SegmentsDistance(p, p, pl[j-1], pl[j])
+						distance = math.Min(distance, d)
+					}
+					require.LessOrEqual(t, distance, tolerance+1e-9, "point %d of the curve", i)
+				}
+			})
+		}
+	}
+}
+
+func Test_Contour_Transform(t *testing.T) {
+	c := Contour{{Kind: SegmentArc, Start: Point{X: 20, Y: 10}, End: Point{X: 10, Y: 20}, Center: Point{X: 10, Y: 10},
+		RX: 10, RY: 10, Sweep: math.Pi / 2}}
+	got := c.Transform(Point{X: 10, Y: 10}, 2)
+	assert.Equal(t, Contour{{Kind: SegmentArc, Start: Point{X: 20, Y: 0}, End: Point{X: 0, Y: 20}, Center: Point{X: 0, Y: 0},
+		RX: 20, RY: 20, Sweep: math.Pi / 2}}, got)
+	pl := got.Flatten(0.1)
+	assert.Equal(t, Point{X: 0, Y: 20}, pl[len(pl)-1])
+}
@@ -12,6 +12,11 @@ type Line struct {
 	p1, p2 point
 }
 
+// NewLine returns the line from start to end.
+func NewLine(start, end Point) Line {
+	return Line{p1: point{x: start.X, y: start.Y}, p2: point{x: end.X, y: end.Y}}
+}
+
 func (l Line) Length() (float64, error) {
 	lx := l.p1.x - l.p2.x
 	ly := l.p1.y - l.p2.y
@@ -1,7 +1,10 @@
 package svg
 
 import (
+	"bytes"
 	"encoding/hex"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"regexp"
@@ -27,23 +30,117 @@ type point struct {
 	x, y float64
 }
 
-// Form defines a svg object that can be measured, sized.
+// Form defines a svg object that can be measured, sized and drawn.
 type Form interface {
 	Length() (float64, error)
 	Bounds() (Bounds, error)
+	// Polylines flattens the form into the strokes it is made of.
+	Polylines() ([]Polyline, error)
+}
+
+// ErrLimitExceeded is returned when a svg source exceeds one of its Limits.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// Limits bounds the resources used to parse a svg source, a zero value disables a limit.
+type Limits struct {
+	// MaxElements is the maximum number of xml elements.
+	MaxElements int
+	// MaxDepth is the maximum nesting depth of xml elements.
+	MaxDepth int
+	// MaxPathCommands is the maximum number of path commands, summed over all paths.
+	MaxPathCommands int
 }
 
 // RetrieveForms retrieves a list of Forms from the svg source.
 func RetrieveForms(source io.Reader, groupID string) (map[string][]Form, error) {
-	svg, err := svgparser.Parse(source, true)
+	return RetrieveFormsWithLimits(source, groupID, Limits{})
+}
+
+// RetrieveFormsWithLimits retrieves a list of Forms from the svg source,
+// failing with ErrLimitExceeded as soon as the source exceeds the limits.
+func RetrieveFormsWithLimits(source io.Reader, groupID string, limits Limits) (map[string][]Form, error) {
+	parsed, err := Parse(source, groupID, limits)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.Forms, nil
+}
+
+// Parsed holds the result of the parsing of a svg source.
+type Parsed struct {
+	// Forms holds the forms found in each group.
+	Forms map[string][]Form
+	// Skipped lists the drawn elements that are not measured.
+	Skipped []Skipped
+}
+
+// Parse retrieves the forms of the svg source and reports the elements it skips,
+// failing with ErrLimitExceeded as soon as the source exceeds the limits.
+func Parse(source io.Reader, groupID string, limits Limits) (Parsed, error) {
+	raw, err := io.ReadAll(source)
+	if err != nil {
+		return Parsed{}, fmt.Errorf("reading svg file: %w", err)
+	}
+	if err := checkStructure(raw, limits); err != nil {
+		return Parsed{}, err
+	}
+
+	svg, err := svgparser.Parse(bytes.NewReader(raw), true)
+	if err != nil {
+		return Parsed{}, fmt.Errorf("parsing svg file: %w", err)
+	}
+
+	p := parser{limits: limits}
+	forms, err := p.parseGroups(svg, groupID)
 	if err != nil {
-		return nil, fmt.Errorf("parsing svg file: %w", err)
+		return Parsed{}, err
 	}
+	return Parsed{Forms: forms, Skipped: p.skipped}, nil
+}
 
-	return parseGroups(svg, groupID)
+// checkStructure streams through the xml elements of raw to check the number of elements
+// and their depth before building the element tree, which is done recursively.
+func checkStructure(raw []byte, limits Limits) error {
+	if limits.MaxElements == 0 && limits.MaxDepth == 0 {
+		return nil
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	var elements, depth int
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("parsing svg file: %w", err)
+		}
+		switch token.(type) {
+		case xml.StartElement:
+			elements++
+			depth++
+			if limits.MaxElements > 0 && elements > limits.MaxElements {
+				return fmt.Errorf("more than %d elements: %w", limits.MaxElements, ErrLimitExceeded)
+			}
+			if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+				return fmt.Errorf("elements nested deeper than %d: %w", limits.MaxDepth, ErrLimitExceeded)
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+}
+
+// parser keeps track of the resources used while parsing forms.
+type parser struct {
+	limits       Limits
+	pathCommands int
+	skipped      []Skipped
 }
 
-func parseForms(element *svgparser.Element) ([]Form, error) {
+func (p *parser) parseForms(group string, element *svgparser.Element) ([]Form, error) {
 	if element == nil {
 		return nil, nil
 	}
@@ -69,15 +166,26 @@ func parseForms(element *svgparser.Element) ([]Form, error) {
 		}
 		forms = append(forms, line)
 	case string(PathType):
-		path, err := parsePath(*element)
+		maxCommands := -1
+		if p.limits.MaxPathCommands > 0 {
+			maxCommands = p.limits.MaxPathCommands - p.pathCommands
+		}
+		path, err := parsePath(*element, maxCommands)
 		if err != nil {
 			return nil, fmt.Errorf("parsing path: %w", err)
 		}
+		for cmd := &path; cmd != nil; cmd = cmd.Next {
+			p.pathCommands++
+		}
 		forms = append(forms, path)
+	default:
+		if unsupportedElements[element.Name] {
+			p.skip(group, element, "unsupported element")
+		}
 	}
 
 	for i, child := range element.Children {
-		childForms, err := parseForms(child)
+		childForms, err := p.parseForms(group, child)
 		if err != nil {
 			return nil, fmt.Errorf("searching forms in child %d: %w", i, err)
 		}
@@ -87,7 +195,7 @@ func parseForms(element *svgparser.Element) ([]Form, error) {
 	return forms, nil
 }
 
-func parseGroups(element *svgparser.Element, groupID string) (map[string][]Form, error) {
+func (p *parser) parseGroups(element *svgparser.Element, groupID string) (map[string][]Form, error) {
 	if element == nil ||
 		(groupID != "" && element.Name == "g" && element.Attributes["id"] != groupID) ||
 		(element.Name == "g" && element.Attributes["id"] == decoupe) {
@@ -97,6 +205,9 @@ func parseGroups(element *svgparser.Element, groupID string) (map[string][]Form,
 	formsGroups := make(map[string][]Form)
 	for _, child := range element.Children {
 		if child.Name != "g" {
+			if drawn(child) {
+				p.skip("", child, "outside of a group")
+			}
 			continue
 		}
 		if groupID != "" && child.Attributes["id"] != groupID {
@@ -108,7 +219,7 @@ func parseGroups(element *svgparser.Element, groupID string) (map[string][]Form,
 			return nil, fmt.Errorf("sanitizing group id %s: %w", child.Attributes["id"], err)
 		}
 
-		formsGroups[groupID], err = parseForms(child)
+		formsGroups[groupID], err = p.parseForms(groupID, child)
 		if err != nil {
 			return nil, fmt.Errorf("parsing group of forms %s: %w", child.Attributes["id"], err)
 		}
@@ -1,9 +1,11 @@
 package svg
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_sanitizeGroupID(t *testing.T) {
@@ -28,3 +30,61 @@ func Test_sanitizeGroupID(t *testing.T) {
 		})
 	}
 }
+
+func Test_RetrieveFormsWithLimits(t *testing.T) {
+	const source = `<svg><g id="_x38_MM"><g><path d="M0,0h10v10"/><rect width="2" height="2"/></g></g></svg>`
+	tests := map[string]struct {
+		limits  Limits
+		wantErr bool
+	}{
+		"no limits": {},
+		"within limits": {
+			limits: Limits{MaxElements: 5, MaxDepth: 4, MaxPathCommands: 3},
+		},
+		"too many elements": {
+			limits:  Limits{MaxElements: 4},
+			wantErr: true,
+		},
+		"too deep": {
+			limits:  Limits{MaxDepth: 3},
+			wantErr: true,
+		},
+		"too many path commands": {
+			limits:  Limits{MaxPathCommands: 2},
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := RetrieveFormsWithLimits(strings.NewReader(source), "", tt.limits)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrLimitExceeded)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, got["8MM"], 2)
+		})
+	}
+}
+
+func Test_Parse_Skipped(t *testing.T) {
+	const source = `<svg>
+	<title>sign</title>
+	<path id="loose" d="M0,0h10"/>
+	<g id="RGB"><path d="M0,0h10"/><ellipse id="oval" rx="2" ry="1" style="fill:blue"/><text>Hi &amp; bye</text></g>
+</svg>`
+	got, err := Parse(strings.NewReader(source), "", Limits{})
+	require.NoError(t, err)
+	assert.Len(t, got.Forms["RGB"], 1)
+	require.Len(t, got.Skipped, 3)
+
+	assert.Equal(t, "", got.Skipped[0].Group)
+	assert.Equal(t, "loose", got.Skipped[0].ID)
+	assert.Equal(t, "outside of a group", got.Skipped[0].Reason)
+
+	assert.Equal(t, "RGB", got.Skipped[1].Group)
+	assert.Equal(t, "ellipse", got.Skipped[1].Element)
+	assert.Equal(t, "unsupported element", got.Skipped[1].Reason)
+	assert.Equal(t, `<ellipse id="oval" rx="2" ry="1" style="stroke:red"></ellipse>`, got.Skipped[1].Markup("stroke:red"))
+	assert.Equal(t, `<text style="fill:red">Hi &amp; bye</text>`, got.Skipped[2].Markup("fill:red"))
+}
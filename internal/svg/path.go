@@ -49,15 +49,32 @@ func (p Path) checkNumberOfParams() bool {
 	return len(p.Parameters)%n == 0
 }
 
+// cursor holds the positions left by the previous commands of a path.
+type cursor struct {
+	firstPos, lastPos, lastCtrl point
+}
+
 func (p Path) Length() (float64, error) {
-	return p.length(point{}, point{}, point{})
+	var length float64
+	var c cursor
+	for cmd := &p; cmd != nil; cmd = cmd.Next {
+		l, next, err := cmd.length(c)
+		if err != nil {
+			return 0, err
+		}
+		length += l
+		c = next
+	}
+	return length, nil
 }
 
-func (p Path) length(firstPos, lastPos, lastCtrl point) (float64, error) {
+// length returns the length of the command alone, and the cursor to measure the next command.
+func (p Path) length(c cursor) (float64, cursor, error) {
 	var length float64
+	firstPos, lastPos, lastCtrl := c.firstPos, c.lastPos, c.lastCtrl
 
 	if !p.checkNumberOfParams() {
-		return 0, fmt.Errorf("invalid number of parameters (%d) for command %c", len(p.Parameters), p.Command)
+		return 0, cursor{}, fmt.Errorf("invalid number of parameters (%d) for command %c", len(p.Parameters), p.Command)
 	}
 	switch p.Command {
 	case 'M':
@@ -198,7 +215,7 @@ func (p Path) length(firstPos, lastPos, lastCtrl point) (float64, error) {
 				p.Parameters[i+4] == 1,
 			)
 			if err != nil {
-				return 0, fmt.Errorf("building arc: %w", err)
+				return 0, cursor{}, fmt.Errorf("building arc: %w", err)
 			}
 			length += arc.length(ellipticArcAngleStep)
 			lastPos = end
@@ -215,7 +232,7 @@ func (p Path) length(firstPos, lastPos, lastCtrl point) (float64, error) {
 				p.Parameters[i+4] == 1,
 			)
 			if err != nil {
-				return 0, fmt.Errorf("building arc: %w", err)
+				return 0, cursor{}, fmt.Errorf("building arc: %w", err)
 			}
 			length += arc.length(ellipticArcAngleStep)
 			lastPos = end
@@ -225,14 +242,7 @@ func (p Path) length(firstPos, lastPos, lastCtrl point) (float64, error) {
 	default:
 		fmt.Printf("Unrecognized path command %c\n", p.Command)
 	}
-	if p.Next != nil {
-		l, err := p.Next.length(firstPos, lastPos, lastCtrl)
-		if err != nil {
-			return 0, err
-		}
-		length += l
-	}
-	return length, nil
+	return length, cursor{firstPos, lastPos, lastCtrl}, nil
 }
 
 func (p Path) Bounds() (Bounds, error) {
@@ -438,6 +448,17 @@ func reflectPoint(p, m point) point {
 	}
 }
 
+// NewPath links the commands into a path.
+func NewPath(commands ...Path) Path {
+	if len(commands) == 0 {
+		return Path{}
+	}
+	for i := len(commands) - 2; i >= 0; i-- {
+		commands[i].Next = &commands[i+1]
+	}
+	return commands[0]
+}
+
 func newPath(str string) (*Path, error) {
 	c := rune(str[0])
 	params, err := parseParam(str[1:])
@@ -451,12 +472,14 @@ func newPath(str string) (*Path, error) {
 	}, nil
 }
 
-func parsePath(element svgparser.Element) (Path, error) {
+// parsePath parses the d attribute of a path element,
+// it fails if the path contains more than maxCommands commands, unless maxCommands is negative.
+func parsePath(element svgparser.Element, maxCommands int) (Path, error) {
 	pathString := element.Attributes["d"]
 	pathString = strings.ReplaceAll(pathString, "\n", "")
 	pathString = strings.ReplaceAll(pathString, " ", "")
 	pathString = strings.ReplaceAll(pathString, "\t", "")
-	path, err := parsePathCommand(pathString)
+	path, err := parsePathCommand(pathString, maxCommands)
 	if err != nil {
 		return Path{}, err
 	}
@@ -466,21 +489,36 @@ func parsePath(element svgparser.Element) (Path, error) {
 	return *path, nil
 }
 
-func parsePathCommand(pathString string) (*Path, error) {
-	commandStr := pathRegex.FindString(pathString)
-	if commandStr == "" {
-		return nil, nil
+func parsePathCommand(pathString string, maxCommands int) (*Path, error) {
+	n := -1
+	if maxCommands >= 0 {
+		n = maxCommands + 1
 	}
-	path, err := newPath(commandStr)
-	if err != nil {
-		return nil, fmt.Errorf("parsing path %s: %w", commandStr, err)
+	locs := pathRegex.FindAllStringIndex(pathString, n)
+	if maxCommands >= 0 && len(locs) > maxCommands {
+		return nil, fmt.Errorf("more than %d path commands: %w", maxCommands, ErrLimitExceeded)
 	}
-	nextString, ok := strings.CutPrefix(pathString, commandStr)
-	if !ok {
-		return nil, fmt.Errorf("prefix %s not found in %s", commandStr, pathString)
+
+	var first, last *Path
+	var end int
+	for _, loc := range locs {
+		commandStr := pathString[loc[0]:loc[1]]
+		if loc[0] != end {
+			return nil, fmt.Errorf("prefix %s not found in %s", commandStr, pathString[end:])
+		}
+		end = loc[1]
+		path, err := newPath(commandStr)
+		if err != nil {
+			return nil, fmt.Errorf("parsing path %s: %w", commandStr, err)
+		}
+		if first == nil {
+			first = path
+		} else {
+			last.Next = path
+		}
+		last = path
 	}
-	path.Next, err = parsePathCommand(nextString)
-	return path, err
+	return first, nil
 }
 
 func parseParam(str string) ([]float64, error) {
@@ -51,10 +51,16 @@ func arcFromSVGParams(start, end point, rx, ry, rot float64, fA, fS bool) (arc,
 	x1 := cosPhi*halfDiffX + sinPhi*halfDiffY
 	y1 := -sinPhi*halfDiffX + cosPhi*halfDiffY
 
+	// radii too small to join the points are scaled up, as svg renderers do.
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+
 	rxy1 := rx * y1
 	ryx1 := ry * x1
 
-	coef := math.Sqrt((rx*rx*ry*ry - rxy1*rxy1 - ryx1*ryx1) / (rxy1*rxy1 + ryx1*ryx1))
+	coef := math.Sqrt(max(0, (rx*rx*ry*ry-rxy1*rxy1-ryx1*ryx1)/(rxy1*rxy1+ryx1*ryx1)))
 	if fA == fS {
 		coef = -coef
 	}
@@ -118,13 +124,10 @@ func (a arc) point(t float64) point {
 }
 
 func (a arc) length(step float64) float64 {
-	deltaAngle := math.Abs(math.Mod(a.endAngle-a.startAngle, math.Pi*2))
-	if !a.clockwise {
-		deltaAngle = math.Pi*2 - deltaAngle
-	}
+	sweep := a.sweep()
 	points := []point{a.start}
-	for t := step; t < deltaAngle; t += step {
-		points = append(points, a.point(a.startAngle+t))
+	for t := step; t < math.Abs(sweep); t += step {
+		points = append(points, a.point(a.startAngle+math.Copysign(t, sweep)))
 	}
 
 	length := lengthLines(points)
@@ -140,12 +143,9 @@ func (a arc) bounds(step float64) Bounds {
 		maxY: max(a.start.y, a.end.y),
 	}
 
-	deltaAngle := math.Abs(math.Mod(a.endAngle-a.startAngle, math.Pi*2))
-	if !a.clockwise {
-		deltaAngle = math.Pi*2 - deltaAngle
-	}
-	for t := step; t < deltaAngle; t += step {
-		b = b.expandPoint(a.point(a.startAngle + t))
+	sweep := a.sweep()
+	for t := step; t < math.Abs(sweep); t += step {
+		b = b.expandPoint(a.point(a.startAngle + math.Copysign(t, sweep)))
 	}
 	return b
 }
@@ -3,9 +3,11 @@ package svg
 import (
 	"fmt"
 	"math"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_arcToCenterParam(t *testing.T) {
@@ -53,6 +55,23 @@ func Test_arcToCenterParam(t *testing.T) {
 				clockwise:  true,
 			},
 		},
+		"radii too small": {
+			start: point{10, 0},
+			end:   point{-10, 0},
+			rx:    5,
+			ry:    5,
+			fS:    true,
+			want: arc{
+				start:      point{10, 0},
+				end:        point{-10, 0},
+				center:     point{0, 0},
+				rx:         10,
+				ry:         10,
+				startAngle: 0,
+				endAngle:   math.Pi,
+				clockwise:  true,
+			},
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -126,3 +145,21 @@ func Test_arc_length(t *testing.T) {
 		})
 	}
 }
+
+// Test_arc_length_fixture measures a design drawn with arcs of sweep flag 0, measured 8346.56
+// along the other side of their ellipse before they were measured on the drawn curve.
+func Test_arc_length_fixture(t *testing.T) {
+	file, err := os.Open("../../data/io.svg")
+	require.NoError(t, err)
+	defer file.Close()
+	forms, err := RetrieveForms(file, "_8MM")
+	require.NoError(t, err)
+
+	var length float64
+	for _, form := range forms["_8MM"] {
+		l, err := form.Length()
+		require.NoError(t, err)
+		length += l
+	}
+	assert.InDelta(t, 7714.23, length, 0.01)
+}
@@ -2,6 +2,7 @@ package svg
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -152,6 +153,17 @@ func Test_Path_Length(t *testing.T) {
 			},
 			want: 213.30,
 		},
+		"11": {
+			path: Path{
+				Command:    'M',
+				Parameters: []float64{0, 10},
+				Next: &Path{
+					Command:    'A',
+					Parameters: []float64{10, 10, 0, 0, 0, 10, 0},
+				},
+			},
+			want: 5 * math.Pi,
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -252,6 +264,20 @@ func Test_Path_Size(t *testing.T) {
 				maxY: 20,
 			},
 		},
+		"6": {
+			path: Path{
+				Command:    'M',
+				Parameters: []float64{0, 10},
+				Next: &Path{
+					Command:    'A',
+					Parameters: []float64{10, 10, 0, 0, 0, 10, 0},
+				},
+			},
+			want: Bounds{
+				maxX: 10,
+				maxY: 10,
+			},
+		},
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -292,7 +318,7 @@ func Test_parsePathCommand(t *testing.T) {
 	}
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			got, err := parsePathCommand(tt.pathString)
+			got, err := parsePathCommand(tt.pathString, -1)
 			require.NoError(t, err)
 			require.NotNil(t, got)
 			assert.Equal(t, tt.want, *got)
@@ -0,0 +1,252 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+)
+
+// flattenArcStep is the angle between two points of a flattened arc or circle.
+const flattenArcStep = math.Pi / 180
+
+// Point is a position in the svg coordinate system.
+type Point struct {
+	X, Y float64
+}
+
+func (p point) export() Point {
+	return Point{X: p.x, Y: p.y}
+}
+
+// Polyline is a stroke flattened into consecutive points,
+// the last point of a closed stroke is equal to its first one.
+type Polyline []Point
+
+// Closed reports whether the polyline ends where it starts.
+func (pl Polyline) Closed() bool {
+	return len(pl) > 2 && pl[0] == pl[len(pl)-1]
+}
+
+// Length returns the sum of the lengths of the polyline segments.
+func (pl Polyline) Length() float64 {
+	var length float64
+	for i := 1; i < len(pl); i++ {
+		length += math.Hypot(pl[i].X-pl[i-1].X, pl[i].Y-pl[i-1].Y)
+	}
+	return length
+}
+
+// Bounds returns the bounds of the points of a non empty polyline.
+func (pl Polyline) Bounds() Bounds {
+	b := Bounds{minX: pl[0].X, maxX: pl[0].X, minY: pl[0].Y, maxY: pl[0].Y}
+	for _, p := range pl[1:] {
+		b = b.expandPoint(point{x: p.X, y: p.Y})
+	}
+	return b
+}
+
+// Contains reports whether p is inside the closed polyline, using the even-odd rule.
+func (pl Polyline) Contains(p Point) bool {
+	if !pl.Closed() {
+		return false
+	}
+	inside := false
+	for i := 1; i < len(pl); i++ {
+		a, b := pl[i-1], pl[i]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < a.X+(p.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func toPolyline(points []point) Polyline {
+	pl := make(Polyline, len(points))
+	for i, p := range points {
+		pl[i] = p.export()
+	}
+	return pl
+}
+
+func (l Line) Polylines() ([]Polyline, error) {
+	return []Polyline{toPolyline([]point{l.p1, l.p2})}, nil
+}
+
+func (r Rectangle) Polylines() ([]Polyline, error) {
+	return []Polyline{toPolyline([]point{
+		r.point,
+		{x: r.x + r.width, y: r.y},
+		{x: r.x + r.width, y: r.y + r.height},
+		{x: r.x, y: r.y + r.height},
+		r.point,
+	})}, nil
+}
+
+func (c Circle) Polylines() ([]Polyline, error) {
+	n := int(math.Round(2 * math.Pi / flattenArcStep))
+	points := make([]point, n+1)
+	for i := 0; i < n; i++ {
+		t := 2 * math.Pi * float64(i) / float64(n)
+		points[i] = point{x: c.x + c.r*math.Cos(t), y: c.y + c.r*math.Sin(t)}
+	}
+	points[n] = points[0]
+	return []Polyline{toPolyline(points)}, nil
+}
+
+func (p Path) Polylines() ([]Polyline, error) {
+	var polylines []Polyline
+	var current []point
+	var firstPos, lastPos, lastCtrl point
+
+	flush := func() {
+		if len(current) > 1 {
+			polylines = append(polylines, toPolyline(current))
+		}
+		current = nil
+	}
+	moveTo := func(pt point) {
+		flush()
+		current = []point{pt}
+		firstPos, lastPos = pt, pt
+	}
+	lineTo := func(points ...point) {
+		if len(current) == 0 {
+			current = []point{lastPos}
+		}
+		current = append(current, points...)
+		lastPos = points[len(points)-1]
+	}
+
+	for cmd := &p; cmd != nil; cmd = cmd.Next {
+		if !cmd.checkNumberOfParams() {
+			return nil, fmt.Errorf("invalid number of parameters (%d) for command %c", len(cmd.Parameters), cmd.Command)
+		}
+		params := cmd.Parameters
+		// origin is added to the coordinates of relative commands.
+		var origin point
+		if cmd.Command >= 'a' && cmd.Command <= 'z' {
+			origin = lastPos
+		}
+		switch cmd.Command {
+		case 'M', 'm':
+			moveTo(point{origin.x + params[0], origin.y + params[1]})
+		case 'H':
+			lineTo(point{params[0], lastPos.y})
+		case 'h':
+			lineTo(point{lastPos.x + params[0], lastPos.y})
+		case 'V':
+			lineTo(point{lastPos.x, params[0]})
+		case 'v':
+			lineTo(point{lastPos.x, lastPos.y + params[0]})
+		case 'L', 'l':
+			for i := 0; i < len(params); i += 2 {
+				if cmd.Command == 'l' {
+					origin = lastPos
+				}
+				lineTo(point{origin.x + params[i], origin.y + params[i+1]})
+			}
+		case 'C', 'c':
+			for i := 0; i < len(params); i += 6 {
+				if cmd.Command == 'c' {
+					origin = lastPos
+				}
+				points := []point{
+					lastPos,
+					{x: origin.x + params[i], y: origin.y + params[i+1]},
+					{x: origin.x + params[i+2], y: origin.y + params[i+3]},
+					{x: origin.x + params[i+4], y: origin.y + params[i+5]},
+				}
+				lineTo(flattenBezier(points)...)
+				lastCtrl = points[2]
+			}
+		case 'S', 's':
+			for i := 0; i < len(params); i += 4 {
+				if cmd.Command == 's' {
+					origin = lastPos
+				}
+				points := []point{
+					lastPos,
+					reflectPoint(lastCtrl, lastPos),
+					{x: origin.x + params[i], y: origin.y + params[i+1]},
+					{x: origin.x + params[i+2], y: origin.y + params[i+3]},
+				}
+				lineTo(flattenBezier(points)...)
+				lastCtrl = points[2]
+			}
+		case 'Q', 'q':
+			for i := 0; i < len(params); i += 4 {
+				if cmd.Command == 'q' {
+					origin = lastPos
+				}
+				points := []point{
+					lastPos,
+					{x: origin.x + params[i], y: origin.y + params[i+1]},
+					{x: origin.x + params[i+2], y: origin.y + params[i+3]},
+				}
+				lineTo(flattenBezier(points)...)
+				lastCtrl = points[1]
+			}
+		case 'T', 't':
+			for i := 0; i < len(params); i += 2 {
+				if cmd.Command == 't' {
+					origin = lastPos
+				}
+				points := []point{
+					lastPos,
+					reflectPoint(lastCtrl, lastPos),
+					{x: origin.x + params[i], y: origin.y + params[i+1]},
+				}
+				lineTo(flattenBezier(points)...)
+				lastCtrl = points[1]
+			}
+		case 'A', 'a':
+			for i := 0; i < len(params); i += 7 {
+				if cmd.Command == 'a' {
+					origin = lastPos
+				}
+				end := point{origin.x + params[i+5], origin.y + params[i+6]}
+				arc, err := arcFromSVGParams(lastPos, end, params[i], params[i+1], params[i+2],
+					params[i+3] == 1, params[i+4] == 1)
+				if err != nil {
+					return nil, fmt.Errorf("building arc: %w", err)
+				}
+				lineTo(arc.flatten(flattenArcStep)...)
+			}
+		case 'Z', 'z':
+			lineTo(firstPos)
+			flush()
+		}
+	}
+	flush()
+	return polylines, nil
+}
+
+// flattenBezier returns the points of the curve after its first control point.
+func flattenBezier(points []point) []point {
+	flattened := make([]point, 0, bezierStep)
+	for i := 1; i < bezierStep; i++ {
+		flattened = append(flattened, splitBezier(float64(i)/bezierStep, points)[0])
+	}
+	return append(flattened, points[len(points)-1])
+}
+
+// sweep returns the signed angle travelled from the start to the end of the arc.
+func (a arc) sweep() float64 {
+	sweep := math.Mod(a.endAngle-a.startAngle, math.Pi*2)
+	if a.clockwise && sweep <= 0 {
+		sweep += math.Pi * 2
+	} else if !a.clockwise && sweep >= 0 {
+		sweep -= math.Pi * 2
+	}
+	return sweep
+}
+
+// flatten returns the points of the arc after its start, separated by at most step radians.
+func (a arc) flatten(step float64) []point {
+	sweep := a.sweep()
+	n := int(math.Ceil(math.Abs(sweep) / step))
+	points := make([]point, 0, n)
+	for i := 1; i < n; i++ {
+		points = append(points, a.point(a.startAngle+sweep*float64(i)/float64(n)))
+	}
+	return append(points, a.end)
+}
@@ -0,0 +1,95 @@
+package svg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Path_Polylines(t *testing.T) {
+	tests := map[string]struct {
+		pathString  string
+		wantCount   int
+		wantClosed  bool
+		wantLastEnd Point
+	}{
+		"lines": {
+			pathString:  "M10,10h10v10H10z",
+			wantCount:   1,
+			wantClosed:  true,
+			wantLastEnd: Point{X: 10, Y: 10},
+		},
+		"two sub paths": {
+			pathString:  "M0,0l10,0M20,0l0,10",
+			wantCount:   2,
+			wantLastEnd: Point{X: 20, Y: 10},
+		},
+		"curves": {
+			pathString:  "M715,371.73h3.29c26.16,3.52,97.36,16.63,161,75.59,73.24,67.81,88,151.38,91.47,175.83",
+			wantCount:   1,
+			wantLastEnd: Point{X: 970.76, Y: 623.15},
+		},
+		"large counter clockwise arc": {
+			pathString:  "M10,0A10,10,0,1,0,0,10",
+			wantCount:   1,
+			wantLastEnd: Point{X: 0, Y: 10},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, err := parsePathCommand(tt.pathString, -1)
+			require.NoError(t, err)
+			polylines, err := path.Polylines()
+			require.NoError(t, err)
+			require.Len(t, polylines, tt.wantCount)
+
+			last := polylines[len(polylines)-1]
+			assert.Equal(t, tt.wantClosed, last.Closed())
+			assert.InDelta(t, tt.wantLastEnd.X, last[len(last)-1].X, 0.01)
+			assert.InDelta(t, tt.wantLastEnd.Y, last[len(last)-1].Y, 0.01)
+
+			// flattening keeps the length of the path.
+			var length float64
+			for _, pl := range polylines {
+				length += pl.Length()
+			}
+			want, err := path.Length()
+			require.NoError(t, err)
+			assert.InDelta(t, want, length, want*0.001)
+		})
+	}
+}
+
+func Test_arc_flatten(t *testing.T) {
+	// three quarters of a circle going through negative angles, from (10,0) to (0,10).
+	a, err := arcFromSVGParams(point{10, 0}, point{0, 10}, 10, 10, 0, true, false)
+	require.NoError(t, err)
+	points := a.flatten(flattenArcStep)
+	mid := points[len(points)/3]
+	assert.InDelta(t, 0, mid.x, 0.5)
+	assert.InDelta(t, -10, mid.y, 0.5)
+}
+
+func Test_Polyline_Contains(t *testing.T) {
+	square := Polyline{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}, {X: 0, Y: 0}}
+	tests := map[string]struct {
+		polyline Polyline
+		point    Point
+		want     bool
+	}{
+		"inside":     {polyline: square, point: Point{X: 5, Y: 5}, want: true},
+		"outside":    {polyline: square, point: Point{X: 15, Y: 5}},
+		"above":      {polyline: square, point: Point{X: 5, Y: -1}},
+		"not closed": {polyline: square[:4], point: Point{X: 5, Y: 5}},
+		"concave": {
+			polyline: Polyline{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}, {X: 5, Y: 2}, {X: 0, Y: 10}, {X: 0, Y: 0}},
+			point:    Point{X: 5, Y: 8},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.polyline.Contains(tt.point))
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+
+	"github.com/JoshVarga/svgparser"
+)
+
+// unsupportedElements are the shapes that are drawn but not measured.
+var unsupportedElements = map[string]bool{
+	"ellipse":  true,
+	"polyline": true,
+	"polygon":  true,
+	"text":     true,
+	"image":    true,
+	"use":      true,
+}
+
+// Skipped is a drawn element of a svg source that is not measured.
+type Skipped struct {
+	// Group is the id of the group holding the element, empty if the element is outside of groups.
+	Group string
+	// Element is the name of the element, as rect or text.
+	Element string
+	ID      string
+	Reason  string
+
+	element *svgparser.Element
+}
+
+func (p *parser) skip(group string, element *svgparser.Element, reason string) {
+	p.skipped = append(p.skipped, Skipped{
+		Group:   group,
+		Element: element.Name,
+		ID:      element.Attributes["id"],
+		Reason:  reason,
+		element: element,
+	})
+}
+
+// drawn reports whether the element or one of its children is a shape.
+func drawn(element *svgparser.Element) bool {
+	switch FormType(element.Name) {
+	case RectangleType, CircleType, PathType, LineType:
+		return true
+	}
+	if unsupportedElements[element.Name] {
+		return true
+	}
+	return slices.ContainsFunc(element.Children, drawn)
+}
+
+// Markup returns the element as svg markup, the style of the element and its children
+// being replaced by style.
+func (s Skipped) Markup(style string) string {
+	var buf bytes.Buffer
+	writeMarkup(&buf, s.element, style)
+	return buf.String()
+}
+
+func writeMarkup(buf *bytes.Buffer, element *svgparser.Element, style string) {
+	buf.WriteString("<" + element.Name)
+	names := make([]string, 0, len(element.Attributes))
+	for name := range element.Attributes {
+		if name != "style" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	names = append(names, "style")
+	for _, name := range names {
+		value := element.Attributes[name]
+		if name == "style" {
+			value = style
+		}
+		buf.WriteString(" " + name + `="`)
+		_ = xml.EscapeText(buf, []byte(value))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">")
+	_ = xml.EscapeText(buf, []byte(element.Content))
+	for _, child := range element.Children {
+		writeMarkup(buf, child, style)
+	}
+	buf.WriteString("</" + element.Name + ">")
+}
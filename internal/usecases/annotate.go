@@ -0,0 +1,60 @@
+package usecases
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/render"
+	"theo303/neon-pricer/internal/svg"
+)
+
+// ErrNotSVG is returned when annotating a design that is not a svg file.
+var ErrNotSVG = errors.New("measures are only drawn over svg designs")
+
+// WriteAnnotations writes the design with the measures of each of its strokes drawn over it,
+// and returns the elements of the design that are not measured.
+func WriteAnnotations(w io.Writer, fileName string, file []byte, config conf.Configuration) ([]svg.Skipped, error) {
+	if DesignFormat(fileName, file) != DesignSVG {
+		return nil, ErrNotSVG
+	}
+	parsed, err := svg.Parse(bytes.NewReader(file), "", svg.Limits{})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+	}
+
+	groups := make([]string, 0, len(parsed.Forms))
+	for g := range parsed.Forms {
+		groups = append(groups, g)
+	}
+	slices.Sort(groups)
+
+	annotations := render.Annotations{Skipped: parsed.Skipped}
+	for _, id := range groups {
+		group := render.AnnotatedGroup{ID: id}
+		for i, form := range parsed.Forms[id] {
+			length, err := form.Length()
+			if err != nil {
+				return nil, fmt.Errorf("%w: measuring form n %d of group %s: %w", ErrInvalidDesign, i, id, err)
+			}
+			polylines, err := form.Polylines()
+			if err != nil {
+				return nil, fmt.Errorf("%w: flattening form n %d of group %s: %w", ErrInvalidDesign, i, id, err)
+			}
+			group.Strokes = append(group.Strokes, render.AnnotatedStroke{
+				Polylines: polylines,
+				LengthMm:  length * 1000 / config.Scale,
+			})
+			group.LengthMm += length * 1000 / config.Scale
+		}
+		annotations.Groups = append(annotations.Groups, group)
+	}
+
+	if err := render.Annotate(w, file, annotations); err != nil {
+		return nil, fmt.Errorf("annotating design: %w", err)
+	}
+	return parsed.Skipped, nil
+}
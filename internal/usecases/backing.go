@@ -0,0 +1,316 @@
+package usecases
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/svg"
+)
+
+// maxContourCells is the number of cells of the largest side of the grid the contour is traced on.
+const maxContourCells = 1000
+
+// retrieveDesign parses the forms of the svg or DXF file, with its backing generated as configured.
+// The format is given by the extension of the file name, if any, or by the content of the file.
+func retrieveDesign(fileName string, file []byte, config conf.Configuration, limits svg.Limits) (map[string][]svg.Form, error) {
+	parsed, err := parseDesign(fileName, file, "", config.Scale, limits)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+	}
+	forms, err := GenerateBacking(parsed.Forms, config)
+	if err != nil {
+		return nil, fmt.Errorf("%w: generating backing: %w", ErrInvalidDesign, err)
+	}
+	return forms, nil
+}
+
+// offsetStroke is a stroke and the distance in svg units the backing keeps around it.
+type offsetStroke struct {
+	polyline svg.Polyline
+	offset   float64
+}
+
+// GenerateBacking returns the forms of a design without DECOUPE group with a DECOUPE group added,
+// outlining the backing generated around the strokes with the mode of the configuration: their
+// bounds as a rectangle or a rounded rectangle, or their contour. The backing is kept at the margin
+// of the edges of the silicones. Other designs are returned as is.
+func GenerateBacking(forms map[string][]svg.Form, config conf.Configuration) (map[string][]svg.Form, error) {
+	mode := config.Backing.Mode
+	if mode == "" || mode == conf.BackingNone {
+		return forms, nil
+	}
+	if _, ok := forms["DECOUPE"]; ok {
+		return forms, nil
+	}
+	polylines, err := GetPolylines(forms)
+	if err != nil {
+		return nil, fmt.Errorf("computing strokes: %w", err)
+	}
+	toSVG := config.Scale / 1000
+	var strokes []offsetStroke
+	for id, pls := range polylines {
+		siliconeSize, err := getSiliconeSize(strings.ToUpper(id))
+		if err != nil {
+			return nil, fmt.Errorf("retrieving silicone size: %w", err)
+		}
+		if siliconeSize == 0 {
+			continue
+		}
+		offset := (config.Backing.MarginMm + float64(siliconeSize)/2) * toSVG
+		for _, pl := range pls {
+			if len(pl) > 0 {
+				strokes = append(strokes, offsetStroke{polyline: pl, offset: offset})
+			}
+		}
+	}
+	if len(strokes) == 0 {
+		return forms, nil
+	}
+
+	var backing []svg.Form
+	switch mode {
+	case conf.BackingRectangle, conf.BackingRounded:
+		bounds := offsetBounds(strokes[0])
+		for _, s := range strokes[1:] {
+			bounds = bounds.Expand(offsetBounds(s))
+		}
+		var radius float64
+		if mode == conf.BackingRounded {
+			radius = min(config.Backing.CornerRadiusMm*toSVG, bounds.Width()/2, bounds.Height()/2)
+		}
+		backing = []svg.Form{roundedRectangle(bounds, radius)}
+	case conf.BackingContour:
+		for _, outline := range contourOutlines(strokes) {
+			backing = append(backing, polygonPath(outline))
+		}
+	default:
+		return nil, fmt.Errorf("unknown backing mode %q", mode)
+	}
+
+	withBacking := make(map[string][]svg.Form, len(forms)+1)
+	for id, f := range forms {
+		withBacking[id] = f
+	}
+	withBacking["DECOUPE"] = backing
+	return withBacking, nil
+}
+
+func offsetBounds(s offsetStroke) svg.Bounds {
+	b := s.polyline.Bounds()
+	lo, hi := b.Min(), b.Max()
+	return svg.Polyline{{X: lo.X - s.offset, Y: lo.Y - s.offset}, {X: hi.X + s.offset, Y: hi.Y + s.offset}}.Bounds()
+}
+
+// roundedRectangle returns the path of the bounds with corners of radius r, square if r is 0.
+func roundedRectangle(b svg.Bounds, r float64) svg.Path {
+	lo, hi := b.Min(), b.Max()
+	if r <= 0 {
+		return svg.NewPath(
+			svg.Path{Command: 'M', Parameters: []float64{lo.X, lo.Y}},
+			svg.Path{Command: 'H', Parameters: []float64{hi.X}},
+			svg.Path{Command: 'V', Parameters: []float64{hi.Y}},
+			svg.Path{Command: 'H', Parameters: []float64{lo.X}},
+			svg.Path{Command: 'Z'},
+		)
+	}
+	corner := func(x, y float64) svg.Path {
+		return svg.Path{Command: 'A', Parameters: []float64{r, r, 0, 0, 1, x, y}}
+	}
+	return svg.NewPath(
+		svg.Path{Command: 'M', Parameters: []float64{lo.X + r, lo.Y}},
+		svg.Path{Command: 'H', Parameters: []float64{hi.X - r}},
+		corner(hi.X, lo.Y+r),
+		svg.Path{Command: 'V', Parameters: []float64{hi.Y - r}},
+		corner(hi.X-r, hi.Y),
+		svg.Path{Command: 'H', Parameters: []float64{lo.X + r}},
+		corner(lo.X, hi.Y-r),
+		svg.Path{Command: 'V', Parameters: []float64{lo.Y + r}},
+		corner(lo.X+r, lo.Y),
+		svg.Path{Command: 'Z'},
+	)
+}
+
+// polygonPath returns the closed path going through the points of the closed polyline,
+// with a line command for each point.
+func polygonPath(pl svg.Polyline) svg.Path {
+	commands := []svg.Path{{Command: 'M', Parameters: []float64{pl[0].X, pl[0].Y}}}
+	for _, p := range pl[1 : len(pl)-1] {
+		commands = append(commands, svg.Path{Command: 'L', Parameters: []float64{p.X, p.Y}})
+	}
+	return svg.NewPath(append(commands, svg.Path{Command: 'Z'})...)
+}
+
+// contourOutlines returns the outlines of the area closer to the strokes than their offsets,
+// without the holes inside it. The area is drawn on a grid of cells an eighth of the smallest
+// offset large, its outlines are traced along the edges of the cells and simplified.
+func contourOutlines(strokes []offsetStroke) []svg.Polyline {
+	bounds := offsetBounds(strokes[0])
+	smallest := strokes[0].offset
+	for _, s := range strokes[1:] {
+		bounds = bounds.Expand(offsetBounds(s))
+		smallest = min(smallest, s.offset)
+	}
+	cell := max(smallest/8, math.Max(bounds.Width(), bounds.Height())/maxContourCells)
+	if cell <= 0 {
+		return nil
+	}
+	// the grid has an empty border of one cell, so the outside is connected.
+	origin := svg.Point{X: bounds.Min().X - cell, Y: bounds.Min().Y - cell}
+	cols := int(math.Ceil(bounds.Width()/cell)) + 2
+	rows := int(math.Ceil(bounds.Height()/cell)) + 2
+	filled := make([]bool, cols*rows)
+	center := func(i, j int) svg.Point {
+		return svg.Point{X: origin.X + (float64(i)+0.5)*cell, Y: origin.Y + (float64(j)+0.5)*cell}
+	}
+	cellOf := func(v float64, o float64, n int) int {
+		return min(max(int(math.Floor((v-o)/cell)), 0), n-1)
+	}
+
+	for _, s := range strokes {
+		pl := s.polyline
+		if len(pl) == 1 {
+			pl = svg.Polyline{pl[0], pl[0]}
+		}
+		for k := 1; k < len(pl); k++ {
+			a, b := pl[k-1], pl[k]
+			i0, i1 := cellOf(min(a.X, b.X)-s.offset, origin.X, cols), cellOf(max(a.X, b.X)+s.offset, origin.X, cols)
+			j0, j1 := cellOf(min(a.Y, b.Y)-s.offset, origin.Y, rows), cellOf(max(a.Y, b.Y)+s.offset, origin.Y, rows)
+			for j := j0; j <= j1; j++ {
+				for i := i0; i <= i1; i++ {
+					if filled[j*cols+i] {
+						continue
+					}
+					c := center(i, j)
+					if d, _ := svg.SegmentsDistance(c, c, a, b); d <= s.offset {
+						filled[j*cols+i] = true
+					}
+				}
+			}
+		}
+	}
+
+	// the empty cells not reachable from the border are holes, filled as part of the backing.
+	outside := make([]bool, cols*rows)
+	queue := []int{0}
+	outside[0] = true
+	for len(queue) > 0 {
+		c := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		i, j := c%cols, c/cols
+		for _, n := range [][2]int{{i - 1, j}, {i + 1, j}, {i, j - 1}, {i, j + 1}} {
+			if n[0] < 0 || n[0] >= cols || n[1] < 0 || n[1] >= rows {
+				continue
+			}
+			k := n[1]*cols + n[0]
+			if !filled[k] && !outside[k] {
+				outside[k] = true
+				queue = append(queue, k)
+			}
+		}
+	}
+	inside := func(i, j int) bool {
+		return i >= 0 && i < cols && j >= 0 && j < rows && !outside[j*cols+i]
+	}
+
+	// the edges between inside and outside cells go clockwise around the inside cells,
+	// from and to the corners of the cells.
+	type corner struct{ i, j int }
+	next := make(map[corner][]corner)
+	for j := 0; j < rows; j++ {
+		for i := 0; i < cols; i++ {
+			if !inside(i, j) {
+				continue
+			}
+			if !inside(i, j-1) {
+				next[corner{i, j}] = append(next[corner{i, j}], corner{i + 1, j})
+			}
+			if !inside(i+1, j) {
+				next[corner{i + 1, j}] = append(next[corner{i + 1, j}], corner{i + 1, j + 1})
+			}
+			if !inside(i, j+1) {
+				next[corner{i + 1, j + 1}] = append(next[corner{i + 1, j + 1}], corner{i, j + 1})
+			}
+			if !inside(i-1, j) {
+				next[corner{i, j + 1}] = append(next[corner{i, j + 1}], corner{i, j})
+			}
+		}
+	}
+
+	var outlines []svg.Polyline
+	for j := 0; j <= rows; j++ {
+		for i := 0; i <= cols; i++ {
+			start := corner{i, j}
+			for len(next[start]) > 0 {
+				var loop svg.Polyline
+				c := start
+				for {
+					loop = append(loop, svg.Point{X: origin.X + float64(c.i)*cell, Y: origin.Y + float64(c.j)*cell})
+					out := next[c]
+					if len(out) == 0 {
+						break
+					}
+					next[c] = out[1:]
+					c = out[0]
+					if c == start {
+						loop = append(loop, loop[0])
+						break
+					}
+				}
+				if loop.Closed() {
+					outlines = append(outlines, simplify(loop, cell))
+				}
+			}
+		}
+	}
+	return outlines
+}
+
+// simplify removes the points of the polyline closer than tolerance to the line
+// between the points kept around them, using the Douglas-Peucker algorithm.
+func simplify(pl svg.Polyline, tolerance float64) svg.Polyline {
+	if len(pl) < 3 {
+		return pl
+	}
+	keep := make([]bool, len(pl))
+	keep[0], keep[len(pl)-1] = true, true
+	var split func(from, to int)
+	split = func(from, to int) {
+		farthest, distance := -1, tolerance
+		for k := from + 1; k < to; k++ {
+			if d, _ := svg.SegmentsDistance(pl[k], pl[k], pl[from], pl[to]); d > distance {
+				farthest, distance = k, d
+			}
+		}
+		if farthest < 0 {
+			return
+		}
+		keep[farthest] = true
+		split(from, farthest)
+		split(farthest, to)
+	}
+	// closed polylines are split at their point farthest from their start,
+	// their start and end being the same point.
+	mid := len(pl) - 1
+	if pl.Closed() {
+		mid, best := 1, 0.0
+		for k := 1; k < len(pl)-1; k++ {
+			if d := math.Hypot(pl[k].X-pl[0].X, pl[k].Y-pl[0].Y); d > best {
+				mid, best = k, d
+			}
+		}
+		keep[mid] = true
+		split(0, mid)
+		split(mid, len(pl)-1)
+	} else {
+		split(0, mid)
+	}
+	simplified := make(svg.Polyline, 0, len(pl))
+	for k, p := range pl {
+		if keep[k] {
+			simplified = append(simplified, p)
+		}
+	}
+	return simplified
+}
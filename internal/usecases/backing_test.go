@@ -0,0 +1,130 @@
+package usecases
+
+import (
+	"strings"
+	"testing"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GenerateBacking(t *testing.T) {
+	const (
+		lines = `<svg><g id="6MM"><line x1="0" y1="0" x2="100" y2="0"/><line x1="0" y1="200" x2="100" y2="200"/></g></svg>`
+		ring  = `<svg><g id="6MM"><rect width="200" height="200"/></g></svg>`
+	)
+	tests := map[string]struct {
+		mode    string
+		design  string
+		inside  []svg.Point
+		outside []svg.Point
+		// wantOutlines is the number of outlines of the backing.
+		wantOutlines int
+	}{
+		"rectangle": {
+			mode:         conf.BackingRectangle,
+			design:       lines,
+			inside:       []svg.Point{{X: -12, Y: -12}, {X: 50, Y: 100}, {X: 112, Y: 212}},
+			outside:      []svg.Point{{X: -14, Y: 0}, {X: 50, Y: 214}},
+			wantOutlines: 1,
+		},
+		"rounded rectangle": {
+			mode:         conf.BackingRounded,
+			design:       lines,
+			inside:       []svg.Point{{X: 0, Y: 0}, {X: 50, Y: 100}, {X: -12, Y: 100}},
+			outside:      []svg.Point{{X: -12, Y: -12}, {X: 112, Y: 212}},
+			wantOutlines: 1,
+		},
+		"contour of separate strokes": {
+			mode:         conf.BackingContour,
+			design:       lines,
+			inside:       []svg.Point{{X: 50, Y: 10}, {X: 50, Y: 190}, {X: -10, Y: 0}},
+			outside:      []svg.Point{{X: 50, Y: 100}, {X: -12, Y: -12}},
+			wantOutlines: 2,
+		},
+		"contour filled inside closed strokes": {
+			mode:         conf.BackingContour,
+			design:       ring,
+			inside:       []svg.Point{{X: 100, Y: 100}, {X: -10, Y: 100}},
+			outside:      []svg.Point{{X: 100, Y: -15}},
+			wantOutlines: 1,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := testConfiguration()
+			config.Scale = 1000
+			config.Backing = conf.Backing{Mode: tt.mode, MarginMm: 10, CornerRadiusMm: 20}
+			forms, err := svg.RetrieveForms(strings.NewReader(tt.design), "")
+			require.NoError(t, err)
+
+			got, err := GenerateBacking(forms, config)
+			require.NoError(t, err)
+			assert.NotContains(t, forms, "DECOUPE", "the forms given are not modified")
+			assert.Equal(t, forms["6MM"], got["6MM"])
+			polylines, err := GetPolylines(map[string][]svg.Form{"DECOUPE": got["DECOUPE"]})
+			require.NoError(t, err)
+			outlines := polylines["DECOUPE"]
+			require.Len(t, outlines, tt.wantOutlines)
+			for _, pl := range outlines {
+				assert.True(t, pl.Closed())
+			}
+			for _, p := range tt.inside {
+				assert.True(t, insideOutlines(outlines, p), "%v is inside the backing", p)
+			}
+			for _, p := range tt.outside {
+				assert.False(t, insideOutlines(outlines, p), "%v is outside the backing", p)
+			}
+		})
+	}
+}
+
+func Test_GenerateBacking_unchanged(t *testing.T) {
+	tests := map[string]struct {
+		mode   string
+		design string
+	}{
+		"no backing mode": {
+			mode:   conf.BackingNone,
+			design: `<svg><g id="6MM"><line x1="0" y1="0" x2="100" y2="0"/></g></svg>`,
+		},
+		"supplied DECOUPE group": {
+			mode: conf.BackingContour,
+			design: `<svg><g id="6MM"><line x1="0" y1="0" x2="100" y2="0"/></g>` +
+				`<g id="DECOUPE"><rect width="10" height="10"/></g></svg>`,
+		},
+		"no stroke": {
+			mode:   conf.BackingRectangle,
+			design: `<svg><g id="TEXTE"><line x1="0" y1="0" x2="100" y2="0"/></g></svg>`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := testConfiguration()
+			config.Scale = 1000
+			config.Backing = conf.Backing{Mode: tt.mode, MarginMm: 10}
+			forms, err := svg.RetrieveForms(strings.NewReader(tt.design), "")
+			require.NoError(t, err)
+			got, err := GenerateBacking(forms, config)
+			require.NoError(t, err)
+			assert.Equal(t, forms, got)
+		})
+	}
+}
+
+func Test_NewQuote_generatedBacking(t *testing.T) {
+	config := testConfiguration()
+	config.Scale = 1000
+	config.Backing = conf.Backing{Mode: conf.BackingRectangle, MarginMm: 30}
+
+	got, err := NewQuote("sign.svg", []byte(`<svg><g id="6MM"><line x1="0" y1="0" x2="1000" y2="0"/></g></svg>`),
+		config, "", 1, svg.Limits{})
+	require.NoError(t, err)
+	require.Contains(t, got.Sizes, "DECOUPE")
+	assert.InDelta(t, 1066, got.Sizes["DECOUPE"].Width, 1e-9)
+	assert.InDelta(t, 66, got.Sizes["DECOUPE"].Height, 1e-9)
+	assert.Equal(t, 3.52, got.Prices["DECOUPE"].PlexiPrice)
+}
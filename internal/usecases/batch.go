@@ -0,0 +1,115 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+)
+
+// ErrNoFile is returned when a pattern matches no design file.
+var ErrNoFile = errors.New("no design file found")
+
+// IsDesignFile reports whether the path is the one of a svg or DXF file, given by its extension.
+func IsDesignFile(path string) bool {
+	ext := filepath.Ext(path)
+	return strings.EqualFold(ext, ".svg") || strings.EqualFold(ext, ".dxf")
+}
+
+// FindDesignFiles returns the svg and DXF files designated by the patterns, sorted and without duplicates.
+// A pattern is a file, a directory searched recursively, or a glob.
+func FindDesignFiles(patterns []string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", pattern, err)
+		}
+		found := 0
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				files = append(files, match)
+				found++
+				continue
+			}
+			err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && IsDesignFile(path) {
+					files = append(files, path)
+					found++
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("walking %s: %w", match, err)
+			}
+		}
+		if found == 0 {
+			return nil, fmt.Errorf("%s: %w", pattern, ErrNoFile)
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// BatchItem is the result of the quoting of one file of a batch.
+type BatchItem struct {
+	Path  string
+	Quote domain.Quote
+	Err   error
+}
+
+// QuoteFiles quotes each file with NewQuote, processing at most workers files at once.
+// The items are returned in the order of the paths, a failing file does not stop the others.
+func QuoteFiles(paths []string, workers int, config conf.Configuration, plexi string, quantity int, limits svg.Limits) []BatchItem {
+	items := make([]BatchItem, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				items[i] = quoteFile(paths[i], config, plexi, quantity, limits)
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return items
+}
+
+// quoteFile quotes a file of a batch, turning a panic into an error so the batch goes on.
+func quoteFile(path string, config conf.Configuration, plexi string, quantity int, limits svg.Limits) (item BatchItem) {
+	item.Path = path
+	defer func() {
+		if r := recover(); r != nil {
+			item.Err = fmt.Errorf("%w: unexpected failure: %v", ErrInvalidDesign, r)
+		}
+	}()
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		item.Err = fmt.Errorf("reading file: %w", err)
+		return item
+	}
+	item.Quote, item.Err = NewQuote(filepath.Base(path), file, config, plexi, quantity, limits)
+	return item
+}
@@ -0,0 +1,85 @@
+package usecases
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FindDesignFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.svg", "b.SVG", "notes.txt", "sub/c.svg", "sub/d.DXF"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("<svg/>"), 0o644))
+	}
+
+	tests := map[string]struct {
+		patterns []string
+		want     []string
+		wantErr  error
+	}{
+		"directory": {
+			patterns: []string{dir},
+			want:     []string{"a.svg", "b.SVG", "sub/c.svg", "sub/d.DXF"},
+		},
+		"glob and file without duplicates": {
+			patterns: []string{filepath.Join(dir, "*.svg"), filepath.Join(dir, "a.svg")},
+			want:     []string{"a.svg"},
+		},
+		"no match": {
+			patterns: []string{filepath.Join(dir, "*.pdf")},
+			wantErr:  ErrNoFile,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := FindDesignFiles(tt.patterns)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			want := make([]string, len(tt.want))
+			for i, name := range tt.want {
+				want[i] = filepath.Join(dir, filepath.FromSlash(name))
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func Test_QuoteFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"good.svg":   `<svg><g id="6MM"><line x1="0" y1="0" x2="1000" y2="0"/></g></svg>`,
+		"broken.svg": `<svg><g id="6MM"><circle r="x"/></g></svg>`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	paths := []string{
+		filepath.Join(dir, "good.svg"),
+		filepath.Join(dir, "broken.svg"),
+		filepath.Join(dir, "missing.svg"),
+		filepath.Join(dir, "good.svg"),
+	}
+	config := testConfiguration()
+	config.Scale = 1000
+
+	items := QuoteFiles(paths, 2, config, "", 1, svg.Limits{})
+	require.Len(t, items, len(paths))
+	for i, item := range items {
+		assert.Equal(t, paths[i], item.Path)
+	}
+	assert.NoError(t, items[0].Err)
+	assert.Equal(t, 1.55, items[0].Quote.Total)
+	assert.ErrorIs(t, items[1].Err, ErrInvalidDesign)
+	assert.ErrorIs(t, items[2].Err, os.ErrNotExist)
+	assert.NoError(t, items[3].Err)
+}
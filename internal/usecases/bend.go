@@ -0,0 +1,157 @@
+package usecases
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+)
+
+// defaultCornerAngleDeg is the turning angle in degrees above which a vertex is a corner, where the
+// silicone is cut and joined, rather than a point of a curve, when the design rules set none.
+const defaultCornerAngleDeg = 20
+
+// cornerAngle returns in radians the corner angle of the design rules, cornerAngleDeg degrees
+// or the default one if 0.
+func cornerAngle(cornerAngleDeg float64) float64 {
+	if cornerAngleDeg == 0 {
+		cornerAngleDeg = defaultCornerAngleDeg
+	}
+	return cornerAngleDeg * math.Pi / 180
+}
+
+// GetBendViolations returns, for each group, the curves of its strokes bent tighter than the minimum
+// bend radius of its silicone, groups without silicone or minimum bend radius being skipped. It fails
+// if the silicone of a group has no pricing. The vertices turning by more than cornerAngleDeg degrees
+// are corners rather than curves, the default angle being used if 0.
+func GetBendViolations(polylines map[string][]svg.Polyline, silicones []conf.Silicone, scale, cornerAngleDeg float64) (map[string][]domain.BendViolation, error) {
+	violations := make(map[string][]domain.BendViolation)
+	for id, pls := range polylines {
+		siliconeSize, err := getSiliconeSize(strings.ToUpper(id))
+		if err != nil {
+			return nil, fmt.Errorf("retrieving silicone size: %w", err)
+		}
+		if siliconeSize == 0 {
+			continue
+		}
+		silicone, err := getSilicone(silicones, siliconeSize)
+		if err != nil {
+			return nil, err
+		}
+		if silicone.MinBendRadiusMm == 0 {
+			continue
+		}
+		for _, pl := range pls {
+			for _, b := range tightestBends(pl, silicone.MinBendRadiusMm*scale/1000, cornerAngle(cornerAngleDeg)) {
+				radius := b.Radius * 1000 / scale
+				violations[id] = append(violations[id], domain.BendViolation{
+					X:                 b.Point.X,
+					Y:                 b.Point.Y,
+					RadiusMm:          domain.Round(radius),
+					MinRadiusMm:       silicone.MinBendRadiusMm,
+					RecommendedSizeMm: recommendedSilicone(silicones, radius),
+				})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// GetTightestRadiuses returns, for each group with curves, the radius in mm of its tightest curve,
+// the vertices turning by more than cornerAngleDeg degrees being corners as for GetBendViolations.
+// The curves of the groups whose silicone has a minimum bend radius are measured as for
+// GetBendViolations, the others vertex by vertex.
+func GetTightestRadiuses(polylines map[string][]svg.Polyline, silicones []conf.Silicone, scale, cornerAngleDeg float64) map[string]float64 {
+	angle := cornerAngle(cornerAngleDeg)
+	radiuses := make(map[string]float64)
+	for id, pls := range polylines {
+		var span float64
+		if size, err := getSiliconeSize(strings.ToUpper(id)); err == nil && size != 0 {
+			if silicone, err := getSilicone(silicones, size); err == nil {
+				span = bendSpan(silicone.MinBendRadiusMm*scale/1000, angle)
+			}
+		}
+		tightest := math.Inf(1)
+		for _, pl := range pls {
+			for _, b := range pl.Bends(angle, span) {
+				if !b.Corner() {
+					tightest = min(tightest, b.Radius)
+				}
+			}
+		}
+		if !math.IsInf(tightest, 1) {
+			radiuses[id] = tightest * 1000 / scale
+		}
+	}
+	return radiuses
+}
+
+// GetCorners returns, for each group, the number of vertices of its strokes
+// turning by more than angleDeg degrees.
+func GetCorners(polylines map[string][]svg.Polyline, angleDeg float64) map[string]int {
+	corners := make(map[string]int)
+	for id, pls := range polylines {
+		for _, pl := range pls {
+			for _, b := range pl.Bends(angleDeg*math.Pi/180, 0) {
+				if b.Corner() {
+					corners[id]++
+				}
+			}
+		}
+	}
+	return corners
+}
+
+// bendSpan returns the length of stroke the radius of the curves is fitted over: the length the
+// silicone bent to minRadius needs to turn by the corner angle, in radians. A kink turning by less
+// than the corner angle is then never tighter than minRadius on its own, as the silicone is bent around it.
+func bendSpan(minRadius, angle float64) float64 {
+	return minRadius * angle
+}
+
+// tightestBends returns the tightest bend of each curve of the stroke with a radius under minRadius,
+// a curve being a run of consecutive bends under the radius. The vertices turning by more than angle
+// radians are corners.
+func tightestBends(pl svg.Polyline, minRadius, angle float64) []svg.Bend {
+	bends := pl.Bends(angle, bendSpan(minRadius, angle))
+	tooTight := func(b svg.Bend) bool {
+		return !b.Corner() && b.Radius < minRadius
+	}
+
+	var runs []svg.Bend
+	inRun := false
+	for _, b := range bends {
+		switch {
+		case !tooTight(b):
+			inRun = false
+		case !inRun:
+			runs = append(runs, b)
+			inRun = true
+		case b.Radius < runs[len(runs)-1].Radius:
+			runs[len(runs)-1] = b
+		}
+	}
+	// on a closed stroke, the last run goes on with the first one.
+	if pl.Closed() && len(runs) > 1 && tooTight(bends[0]) && tooTight(bends[len(bends)-1]) {
+		last := runs[len(runs)-1]
+		runs = runs[:len(runs)-1]
+		if last.Radius < runs[0].Radius {
+			runs[0] = last
+		}
+	}
+	return runs
+}
+
+// recommendedSilicone returns the size of the largest silicone that can be bent to radiusMm, 0 if none.
+func recommendedSilicone(silicones []conf.Silicone, radiusMm float64) int {
+	var size int
+	for _, s := range silicones {
+		if s.MinBendRadiusMm > 0 && s.MinBendRadiusMm <= radiusMm && s.SizeMm > size {
+			size = s.SizeMm
+		}
+	}
+	return size
+}
@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetBendViolations(t *testing.T) {
+	silicones := []conf.Silicone{
+		{SizeMm: 6, MinBendRadiusMm: 15},
+		{SizeMm: 8, MinBendRadiusMm: 20},
+		{SizeMm: 12, MinBendRadiusMm: 30},
+	}
+	tests := map[string]struct {
+		design          string
+		wantRadius      float64
+		wantRecommended int
+	}{
+		"arc": {
+			design:          `<svg><g id="12MM"><path d="M0,0h100a25,25,0,0,1,0,50h-100"/></g></svg>`,
+			wantRadius:      25,
+			wantRecommended: 8,
+		},
+		// the radius is fitted over the stroke around the tightest point of the curve.
+		"bezier": {
+			design:          `<svg><g id="12MM"><path d="M0,0C40,0,40,40,0,40"/></g></svg>`,
+			wantRadius:      15.4,
+			wantRecommended: 6,
+		},
+		"circle": {
+			design:          `<svg><g id="8MM"><circle cx="0" cy="0" r="12"/></g></svg>`,
+			wantRadius:      12,
+			wantRecommended: 0,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			forms, err := svg.RetrieveForms(strings.NewReader(tt.design), "")
+			require.NoError(t, err)
+			polylines, err := GetPolylines(forms)
+			require.NoError(t, err)
+
+			got, err := GetBendViolations(polylines, silicones, 1000, 0)
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+			for _, violations := range got {
+				require.Len(t, violations, 1)
+				assert.InDelta(t, tt.wantRadius, violations[0].RadiusMm, 0.2)
+				assert.Equal(t, tt.wantRecommended, violations[0].RecommendedSizeMm)
+			}
+		})
+	}
+}
+
+func Test_GetBendViolations_flattenedKinks(t *testing.T) {
+	// the lettering joins its arcs with kinks of up to 45 degrees and its curves with tiny bezier
+	// segments, the radii measured at their vertices alone being under a millimetre.
+	raw, err := os.ReadFile("../../data/long.svg")
+	require.NoError(t, err)
+	parsed, err := parseDesign("long.svg", raw, "", 2834.6457, svg.Limits{})
+	require.NoError(t, err)
+	polylines, err := GetPolylines(parsed.Forms)
+	require.NoError(t, err)
+
+	got, err := GetBendViolations(polylines, []conf.Silicone{{SizeMm: 8, MinBendRadiusMm: 20}}, 2834.6457, 45)
+	require.NoError(t, err)
+	require.NotEmpty(t, got["_8MM"])
+	for _, v := range got["_8MM"] {
+		assert.Greater(t, v.RadiusMm, 5.0, "bend at %v,%v", v.X, v.Y)
+	}
+	radiuses := GetTightestRadiuses(polylines, []conf.Silicone{{SizeMm: 8, MinBendRadiusMm: 20}}, 2834.6457, 45)
+	assert.Greater(t, radiuses["_8MM"], 5.0)
+}
+
+func Test_GetBendViolations_unknownSilicone(t *testing.T) {
+	polylines := map[string][]svg.Polyline{"10MM": {{{X: 0, Y: 0}, {X: 100, Y: 0}}}}
+	_, err := GetBendViolations(polylines, []conf.Silicone{{SizeMm: 6, MinBendRadiusMm: 15}}, 1000, 0)
+	assert.Error(t, err)
+}
+
+func Test_GetTightestRadiuses(t *testing.T) {
+	const design = `<svg><g id="6MM"><circle cx="0" cy="0" r="40"/><circle cx="0" cy="0" r="20"/></g>` +
+		`<g id="DECOUPE"><rect width="100" height="100"/></g></svg>`
+	forms, err := svg.RetrieveForms(strings.NewReader(design), "")
+	require.NoError(t, err)
+	polylines, err := GetPolylines(forms)
+	require.NoError(t, err)
+
+	got := GetTightestRadiuses(polylines, nil, 2000, 0)
+	require.Len(t, got, 1)
+	assert.InDelta(t, 10, got["6MM"], 0.01)
+
+	// the corners of the rectangle are curves when they turn by less than the corner angle.
+	got = GetTightestRadiuses(polylines, nil, 2000, 100)
+	assert.Contains(t, got, "DECOUPE")
+}
+
+func Test_GetCorners(t *testing.T) {
+	const design = `<svg><g id="6MM"><rect width="100" height="50"/><circle cx="0" cy="0" r="20"/></g>` +
+		`<g id="RGB"><path d="M0,0l100,0l50,20l-50,80z"/></g></svg>`
+	forms, err := svg.RetrieveForms(strings.NewReader(design), "")
+	require.NoError(t, err)
+	polylines, err := GetPolylines(forms)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		angleDeg float64
+		want     map[string]int
+	}{
+		"right angles and more": {
+			angleDeg: 45,
+			want:     map[string]int{"6MM": 4, "RGB": 3},
+		},
+		"every vertex of a line": {
+			angleDeg: 10,
+			want:     map[string]int{"6MM": 4, "RGB": 4},
+		},
+		"only u-turns": {
+			angleDeg: 170,
+			want:     map[string]int{},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetCorners(polylines, tt.angleDeg))
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"theo303/neon-pricer/conf"
+)
+
+var (
+	// ErrUnknownCatalogue is returned when a catalogue name does not match any pricing list.
+	ErrUnknownCatalogue = errors.New("unknown catalogue")
+	// ErrItemNotFound is returned when an item is missing from a catalogue.
+	ErrItemNotFound = errors.New("item not found")
+	// ErrDuplicateItem is returned when an item already exists in a catalogue.
+	ErrDuplicateItem = errors.New("item already exists")
+	// ErrInvalidItem is returned when an item name or price is not valid.
+	ErrInvalidItem = errors.New("invalid item")
+)
+
+// Catalogues lists the names of the pricing lists that can be edited,
+// they match the prefixes used in the configuration form.
+var Catalogues = []string{siliconeParam, ledParam, plexiParam, controlerParam, powerSupplyParam}
+
+// AddCatalogueItem adds an item identified by key to the catalogue.
+// Silicones are identified by their size in mm, power supplies by their amperage
+// and other items by their name.
+func AddCatalogueItem(config *conf.Configuration, catalogue, key string, price float64) error {
+	key, err := validateItem(catalogue, key, price)
+	if err != nil {
+		return err
+	}
+	_, err = findItem(config, catalogue, key)
+	if err == nil {
+		return fmt.Errorf("%s %s: %w", catalogue, key, ErrDuplicateItem)
+	}
+	if !errors.Is(err, ErrItemNotFound) {
+		return err
+	}
+
+	switch catalogue {
+	case siliconeParam:
+		size, _ := strconv.Atoi(key)
+		config.Silicones = append(config.Silicones, conf.Silicone{SizeMm: size, PricePerMeter: price})
+	case ledParam:
+		config.LEDs = append(config.LEDs, conf.LED{Name: key, PricePerMeter: price})
+	case plexiParam:
+		config.Plexis = append(config.Plexis, conf.Plexi{Name: key, PricePerMeterSquare: price})
+	case controlerParam:
+		config.Controlers = append(config.Controlers, conf.Controler{Name: key, Price: price})
+	case powerSupplyParam:
+		config.PowerSupplies = append(config.PowerSupplies, conf.PowerSupply{Amp: key, Price: price})
+	}
+	return nil
+}
+
+// RemoveCatalogueItem removes the item identified by key from the catalogue.
+func RemoveCatalogueItem(config *conf.Configuration, catalogue, key string) error {
+	idx, err := findItem(config, catalogue, key)
+	if err != nil {
+		return err
+	}
+
+	switch catalogue {
+	case siliconeParam:
+		config.Silicones = append(config.Silicones[:idx], config.Silicones[idx+1:]...)
+	case ledParam:
+		config.LEDs = append(config.LEDs[:idx], config.LEDs[idx+1:]...)
+	case plexiParam:
+		config.Plexis = append(config.Plexis[:idx], config.Plexis[idx+1:]...)
+	case controlerParam:
+		config.Controlers = append(config.Controlers[:idx], config.Controlers[idx+1:]...)
+	case powerSupplyParam:
+		config.PowerSupplies = append(config.PowerSupplies[:idx], config.PowerSupplies[idx+1:]...)
+	}
+	return nil
+}
+
+// RenameCatalogueItem changes the key of an item, keeping its price.
+func RenameCatalogueItem(config *conf.Configuration, catalogue, key, newKey string) error {
+	idx, err := findItem(config, catalogue, key)
+	if err != nil {
+		return err
+	}
+	newKey, err = validateItem(catalogue, newKey, 0)
+	if err != nil {
+		return err
+	}
+	if newKey == key {
+		return nil
+	}
+	if _, err := findItem(config, catalogue, newKey); err == nil {
+		return fmt.Errorf("%s %s: %w", catalogue, newKey, ErrDuplicateItem)
+	}
+
+	switch catalogue {
+	case siliconeParam:
+		config.Silicones[idx].SizeMm, _ = strconv.Atoi(newKey)
+	case ledParam:
+		config.LEDs[idx].Name = newKey
+	case plexiParam:
+		config.Plexis[idx].Name = newKey
+	case controlerParam:
+		config.Controlers[idx].Name = newKey
+	case powerSupplyParam:
+		config.PowerSupplies[idx].Amp = newKey
+	}
+	return nil
+}
+
+// validateItem checks the key and price of an item and returns the normalized key.
+func validateItem(catalogue, key string, price float64) (string, error) {
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("empty name: %w", ErrInvalidItem)
+	}
+	if strings.Contains(key, "/") {
+		return "", fmt.Errorf("name %q contains a forbidden character: %w", key, ErrInvalidItem)
+	}
+	if price < 0 {
+		return "", fmt.Errorf("negative price %.2f: %w", price, ErrInvalidItem)
+	}
+	if catalogue == siliconeParam {
+		size, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(key), "mm"))
+		if err != nil || size <= 0 {
+			return "", fmt.Errorf("silicone size %s is not a positive integer: %w", key, ErrInvalidItem)
+		}
+		key = strconv.Itoa(size)
+	}
+	return key, nil
+}
+
+// findItem returns the index of the item identified by key in the catalogue.
+func findItem(config *conf.Configuration, catalogue, key string) (int, error) {
+	idx := -1
+	switch catalogue {
+	case siliconeParam:
+		for i, s := range config.Silicones {
+			if strconv.Itoa(s.SizeMm) == key {
+				idx = i
+			}
+		}
+	case ledParam:
+		for i, l := range config.LEDs {
+			if l.Name == key {
+				idx = i
+			}
+		}
+	case plexiParam:
+		for i, p := range config.Plexis {
+			if p.Name == key {
+				idx = i
+			}
+		}
+	case controlerParam:
+		for i, c := range config.Controlers {
+			if c.Name == key {
+				idx = i
+			}
+		}
+	case powerSupplyParam:
+		for i, ps := range config.PowerSupplies {
+			if ps.Amp == key {
+				idx = i
+			}
+		}
+	default:
+		return 0, fmt.Errorf("%s: %w", catalogue, ErrUnknownCatalogue)
+	}
+	if idx < 0 {
+		return 0, fmt.Errorf("%s %s: %w", catalogue, key, ErrItemNotFound)
+	}
+	return idx, nil
+}
@@ -0,0 +1,142 @@
+package usecases
+
+import (
+	"testing"
+	"theo303/neon-pricer/conf"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testConfiguration() conf.Configuration {
+	return conf.Configuration{
+		Pricing: conf.Pricing{
+			Silicones: []conf.Silicone{{SizeMm: 6, PricePerMeter: 0.7}, {SizeMm: 12, PricePerMeter: 1.1}},
+			LEDs:      []conf.LED{{Name: "couleur", PricePerMeter: 0.85}},
+			Plexis:    []conf.Plexi{{Name: "incolore", PricePerMeterSquare: 50}},
+		},
+	}
+}
+
+func Test_AddCatalogueItem(t *testing.T) {
+	tests := map[string]struct {
+		catalogue string
+		key       string
+		price     float64
+		wantKey   string
+		wantErr   error
+	}{
+		"new silicone": {
+			catalogue: siliconeParam,
+			key:       "10mm",
+			price:     0.95,
+			wantKey:   "10",
+		},
+		"new plexi": {
+			catalogue: plexiParam,
+			key:       "miroir",
+			price:     80,
+			wantKey:   "miroir",
+		},
+		"duplicate silicone": {
+			catalogue: siliconeParam,
+			key:       "12",
+			wantErr:   ErrDuplicateItem,
+		},
+		"duplicate led": {
+			catalogue: ledParam,
+			key:       " couleur ",
+			wantErr:   ErrDuplicateItem,
+		},
+		"invalid silicone size": {
+			catalogue: siliconeParam,
+			key:       "big",
+			wantErr:   ErrInvalidItem,
+		},
+		"negative price": {
+			catalogue: controlerParam,
+			key:       "DIMMER",
+			price:     -1,
+			wantErr:   ErrInvalidItem,
+		},
+		"unknown catalogue": {
+			catalogue: "cable",
+			key:       "red",
+			wantErr:   ErrUnknownCatalogue,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := testConfiguration()
+			err := AddCatalogueItem(&config, tt.catalogue, tt.key, tt.price)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Equal(t, testConfiguration(), config)
+				return
+			}
+			assert.NoError(t, err)
+			_, err = findItem(&config, tt.catalogue, tt.wantKey)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_RenameCatalogueItem(t *testing.T) {
+	tests := map[string]struct {
+		catalogue string
+		key       string
+		newKey    string
+		wantErr   error
+	}{
+		"rename silicone": {
+			catalogue: siliconeParam,
+			key:       "6",
+			newKey:    "8",
+		},
+		"rename to itself": {
+			catalogue: ledParam,
+			key:       "couleur",
+			newKey:    "couleur",
+		},
+		"rename to existing": {
+			catalogue: siliconeParam,
+			key:       "6",
+			newKey:    "12",
+			wantErr:   ErrDuplicateItem,
+		},
+		"missing item": {
+			catalogue: plexiParam,
+			key:       "noir",
+			newKey:    "miroir",
+			wantErr:   ErrItemNotFound,
+		},
+		"empty name": {
+			catalogue: plexiParam,
+			key:       "incolore",
+			newKey:    "  ",
+			wantErr:   ErrInvalidItem,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := testConfiguration()
+			err := RenameCatalogueItem(&config, tt.catalogue, tt.key, tt.newKey)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			_, err = findItem(&config, tt.catalogue, tt.newKey)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_RemoveCatalogueItem(t *testing.T) {
+	config := testConfiguration()
+
+	assert.NoError(t, RemoveCatalogueItem(&config, siliconeParam, "6"))
+	assert.Equal(t, []conf.Silicone{{SizeMm: 12, PricePerMeter: 1.1}}, config.Silicones)
+
+	assert.ErrorIs(t, RemoveCatalogueItem(&config, siliconeParam, "6"), ErrItemNotFound)
+	assert.ErrorIs(t, RemoveCatalogueItem(&config, "cable", "red"), ErrUnknownCatalogue)
+}
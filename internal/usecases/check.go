@@ -0,0 +1,276 @@
+package usecases
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+)
+
+// Rules checked on designs.
+const (
+	RuleBendRadius = "bend_radius"
+	RuleSpacing    = "spacing"
+	RuleLEDLength  = "led_length"
+	RulePlexiSheet = "plexi_sheet"
+)
+
+// CheckDesign checks that the design file can be built with the silicones, LEDs and plexi of the
+// configuration and its design rules, and returns the issues found.
+func CheckDesign(fileName string, file []byte, config conf.Configuration, plexi string, limits svg.Limits) ([]domain.Issue, error) {
+	forms, err := retrieveDesign(fileName, file, config, limits)
+	if err != nil {
+		return nil, err
+	}
+	polylines, err := GetPolylines(forms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+	}
+	bounds, err := GetBounds(forms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+	}
+	toMm := 1000 / config.Scale
+
+	ids := make([]string, 0, len(polylines))
+	for id := range polylines {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	violations, err := GetBendViolations(polylines, config.Silicones, config.Scale, config.DesignRules.CornerAngleDeg)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := []domain.Issue{}
+	var strokes []spacedStroke
+	for _, id := range ids {
+		siliconeSize, err := getSiliconeSize(strings.ToUpper(id))
+		if err != nil {
+			return nil, fmt.Errorf("retrieving silicone size: %w", err)
+		}
+		if siliconeSize == 0 {
+			continue
+		}
+		for _, v := range violations[id] {
+			message := fmt.Sprintf("curve of radius %.1fmm is tighter than the %vmm minimum bend radius of the %dmm silicone",
+				v.RadiusMm, v.MinRadiusMm, siliconeSize)
+			if v.RecommendedSizeMm > 0 {
+				message += fmt.Sprintf(", use the %dmm silicone", v.RecommendedSizeMm)
+			}
+			issues = append(issues, domain.Issue{
+				Rule:     RuleBendRadius,
+				Severity: domain.SeverityError,
+				Group:    id,
+				X:        v.X,
+				Y:        v.Y,
+				Value:    v.RadiusMm,
+				Limit:    v.MinRadiusMm,
+				Message:  message,
+			})
+		}
+		led := getLED(config.LEDs, strings.ToUpper(id))
+		for _, pl := range polylines[id] {
+			if length := pl.Length() * toMm; length < led.MinLengthMm {
+				issues = append(issues, domain.Issue{
+					Rule:     RuleLEDLength,
+					Severity: domain.SeverityError,
+					Group:    id,
+					X:        pl[0].X,
+					Y:        pl[0].Y,
+					Value:    domain.Round(length),
+					Limit:    led.MinLengthMm,
+					Message: fmt.Sprintf("stroke of %.1fmm is shorter than the %vmm minimum length of LED %s",
+						length, led.MinLengthMm, led.Name),
+				})
+			}
+			strokes = append(strokes, spacedStroke{group: id, polyline: pl, width: float64(siliconeSize) / toMm})
+		}
+	}
+	if config.DesignRules.MinSpacingMm > 0 {
+		issues = append(issues, checkSpacing(strokes, config.DesignRules.MinSpacingMm, toMm)...)
+	}
+	if issue, ok := checkPlexiSheet(bounds, getPlexi(config.Plexis, plexi), toMm); ok {
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// spacedStroke is a stroke of a group and the width of its silicone, in svg units.
+type spacedStroke struct {
+	group    string
+	polyline svg.Polyline
+	width    float64
+}
+
+// spacedSegment is a segment of a stroke, start and end being its positions along the stroke.
+type spacedSegment struct {
+	stroke     int
+	a, b       svg.Point
+	start, end float64
+}
+
+// checkSpacing reports the strokes whose silicones are closer than minSpacingMm, once for each pair
+// of strokes. The parts of a same stroke are compared when the stroke comes back close to itself,
+// that is when they are further apart along the stroke than π times the distance to keep.
+func checkSpacing(strokes []spacedStroke, minSpacingMm, toMm float64) []domain.Issue {
+	minSpacing := minSpacingMm / toMm
+	var segments []spacedSegment
+	var maxWidth float64
+	lengths := make([]float64, len(strokes))
+	for i, s := range strokes {
+		maxWidth = max(maxWidth, s.width)
+		for j := 1; j < len(s.polyline); j++ {
+			a, b := s.polyline[j-1], s.polyline[j]
+			length := math.Hypot(b.X-a.X, b.Y-a.Y)
+			segments = append(segments, spacedSegment{stroke: i, a: a, b: b, start: lengths[i], end: lengths[i] + length})
+			lengths[i] += length
+		}
+	}
+
+	// segments are indexed in a grid with cells as large as the largest distance to keep, enlarged
+	// so that the segments cross at most maxSpacingCells cells on designs much larger than it.
+	var total float64
+	for _, l := range lengths {
+		total += l
+	}
+	cell := max(minSpacing+maxWidth, total/maxSpacingCells)
+	grid := make(map[[2]int][]int)
+	for i, s := range segments {
+		segmentCells(s.a, s.b, cell, 0, func(c [2]int) {
+			if n := len(grid[c]); n == 0 || grid[c][n-1] != i {
+				grid[c] = append(grid[c], i)
+			}
+		})
+	}
+
+	type closest struct {
+		distance float64
+		point    svg.Point
+	}
+	pairs := make(map[[2]int]closest)
+	var pairKeys [][2]int
+	seen := make([]int, len(segments))
+	for i, s := range segments {
+		segmentCells(s.a, s.b, cell, 1, func(c [2]int) {
+			for _, j := range grid[c] {
+				if j <= i || seen[j] == i+1 {
+					continue
+				}
+				seen[j] = i + 1
+				o := segments[j]
+				keep := minSpacing + (strokes[s.stroke].width+strokes[o.stroke].width)/2
+				if s.stroke == o.stroke {
+					gap := o.start - s.end
+					if strokes[s.stroke].polyline.Closed() {
+						gap = min(gap, lengths[s.stroke]-o.end+s.start)
+					}
+					if gap < math.Pi*keep {
+						continue
+					}
+				}
+				d, p := svg.SegmentsDistance(s.a, s.b, o.a, o.b)
+				if d >= keep {
+					continue
+				}
+				key := [2]int{s.stroke, o.stroke}
+				prev, ok := pairs[key]
+				if !ok {
+					pairKeys = append(pairKeys, key)
+				}
+				if !ok || d < prev.distance {
+					pairs[key] = closest{distance: d, point: p}
+				}
+			}
+		})
+	}
+
+	slices.SortFunc(pairKeys, func(a, b [2]int) int {
+		if a[0] != b[0] {
+			return a[0] - b[0]
+		}
+		return a[1] - b[1]
+	})
+	issues := make([]domain.Issue, 0, len(pairKeys))
+	for _, key := range pairKeys {
+		a, b := strokes[key[0]], strokes[key[1]]
+		c := pairs[key]
+		spacing := max(0, c.distance-(a.width+b.width)/2) * toMm
+		what := fmt.Sprintf("strokes of %s and %s are", a.group, b.group)
+		if key[0] == key[1] {
+			what = fmt.Sprintf("parts of a stroke of %s are", a.group)
+		}
+		issues = append(issues, domain.Issue{
+			Rule:     RuleSpacing,
+			Severity: domain.SeverityWarning,
+			Group:    a.group,
+			X:        c.point.X,
+			Y:        c.point.Y,
+			Value:    domain.Round(spacing),
+			Limit:    minSpacingMm,
+			Message:  fmt.Sprintf("%s %.1fmm apart, %vmm are required between silicones", what, spacing, minSpacingMm),
+		})
+	}
+	return issues
+}
+
+// maxSpacingCells bounds the number of cells of the spacing grid crossed by the segments of a design.
+const maxSpacingCells = 1 << 20
+
+// segmentCells calls fn with the cells of size cell crossed by the segment from a to b, and the margin
+// cells around them. The segment is walked in steps shorter than a cell, a cell possibly being given
+// several times.
+func segmentCells(a, b svg.Point, cell float64, margin int, fn func([2]int)) {
+	steps := max(1, int(math.Ceil(math.Hypot(b.X-a.X, b.Y-a.Y)/cell)))
+	for k := 0; k < steps; k++ {
+		t0, t1 := float64(k)/float64(steps), float64(k+1)/float64(steps)
+		p := svg.Point{X: a.X + t0*(b.X-a.X), Y: a.Y + t0*(b.Y-a.Y)}
+		q := svg.Point{X: a.X + t1*(b.X-a.X), Y: a.Y + t1*(b.Y-a.Y)}
+		x0, x1 := int(math.Floor(min(p.X, q.X)/cell)), int(math.Floor(max(p.X, q.X)/cell))
+		y0, y1 := int(math.Floor(min(p.Y, q.Y)/cell)), int(math.Floor(max(p.Y, q.Y)/cell))
+		for x := x0 - margin; x <= x1+margin; x++ {
+			for y := y0 - margin; y <= y1+margin; y++ {
+				fn([2]int{x, y})
+			}
+		}
+	}
+}
+
+// checkPlexiSheet reports a design larger than the sheets of the plexi, the DECOUPE group
+// being cut from the sheet or, without it, the whole design being mounted on it.
+func checkPlexiSheet(bounds map[string]svg.Bounds, plexi conf.Plexi, toMm float64) (domain.Issue, bool) {
+	if plexi.SheetWidthMm == 0 || plexi.SheetHeightMm == 0 {
+		return domain.Issue{}, false
+	}
+	design, group, ok := backingBounds(bounds)
+	if !ok {
+		return domain.Issue{}, false
+	}
+
+	width, height := design.Width()*toMm, design.Height()*toMm
+	sheetLong, sheetShort := max(plexi.SheetWidthMm, plexi.SheetHeightMm), min(plexi.SheetWidthMm, plexi.SheetHeightMm)
+	long, short := max(width, height), min(width, height)
+	if long <= sheetLong && short <= sheetShort {
+		return domain.Issue{}, false
+	}
+	value, limit := long, sheetLong
+	if long <= sheetLong {
+		value, limit = short, sheetShort
+	}
+	return domain.Issue{
+		Rule:     RulePlexiSheet,
+		Severity: domain.SeverityError,
+		Group:    group,
+		X:        design.Min().X,
+		Y:        design.Min().Y,
+		Value:    domain.Round(value),
+		Limit:    limit,
+		Message: fmt.Sprintf("design of %.0f x %.0fmm does not fit on the %v x %vmm sheets of plexi %s",
+			width, height, plexi.SheetWidthMm, plexi.SheetHeightMm, plexi.Name),
+	}, true
+}
@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"testing"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CheckDesign(t *testing.T) {
+	config := conf.Configuration{
+		Scale: 1000,
+		Pricing: conf.Pricing{
+			Silicones: []conf.Silicone{{SizeMm: 6, MinBendRadiusMm: 15}, {SizeMm: 12, MinBendRadiusMm: 30}},
+			LEDs:      []conf.LED{{Name: "couleur", MinLengthMm: 25}},
+			Plexis:    []conf.Plexi{{Name: "incolore", SheetWidthMm: 1000, SheetHeightMm: 500}},
+		},
+		DesignRules: conf.DesignRules{MinSpacingMm: 5},
+	}
+
+	tests := map[string]struct {
+		design    string
+		wantRules []string
+	}{
+		"valid": {
+			design: `<svg><g id="6MM"><circle cx="100" cy="100" r="20"/><line x1="0" y1="200" x2="300" y2="200"/></g></svg>`,
+		},
+		"tight curve": {
+			design:    `<svg><g id="12MM"><path d="M0,0h100a20,20,0,0,1,0,40h-100"/></g></svg>`,
+			wantRules: []string{RuleBendRadius},
+		},
+		"curve tight for the profile only": {
+			design: `<svg><g id="6MM"><path d="M0,0h100a20,20,0,0,1,0,40h-100"/></g></svg>`,
+		},
+		"short stroke": {
+			design:    `<svg><g id="6MM"><line x1="0" y1="0" x2="20" y2="0"/></g></svg>`,
+			wantRules: []string{RuleLEDLength},
+		},
+		"close strokes": {
+			design:    `<svg><g id="6MM"><line x1="0" y1="0" x2="100" y2="0"/><line x1="0" y1="10" x2="100" y2="10"/></g></svg>`,
+			wantRules: []string{RuleSpacing},
+		},
+		"stroke coming back": {
+			design:    `<svg><g id="6MM"><path d="M0,0h100v60h-100v-52"/></g></svg>`,
+			wantRules: []string{RuleSpacing},
+		},
+		"long diagonal strokes": {
+			design:    `<svg><g id="6MM"><line x1="0" y1="0" x2="1000000" y2="1000000"/><line x1="10" y1="0" x2="1000010" y2="1000000"/></g></svg>`,
+			wantRules: []string{RuleSpacing, RulePlexiSheet},
+		},
+		"corners are not curves": {
+			design: `<svg><g id="12MM"><rect width="100" height="60"/></g></svg>`,
+		},
+		"too large for the plexi": {
+			design:    `<svg><g id="DECOUPE"><rect width="600" height="1200"/></g></svg>`,
+			wantRules: []string{RulePlexiSheet},
+		},
+		"rotated on the plexi": {
+			design: `<svg><g id="DECOUPE"><rect width="400" height="900"/></g></svg>`,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			issues, err := CheckDesign("", []byte(tt.design), config, "", svg.Limits{})
+			require.NoError(t, err)
+			var rules []string
+			for _, issue := range issues {
+				rules = append(rules, issue.Rule)
+			}
+			assert.Equal(t, tt.wantRules, rules)
+		})
+	}
+}
+
+func Test_CheckDesign_location(t *testing.T) {
+	config := conf.Configuration{
+		Scale:       2000,
+		Pricing:     conf.Pricing{Silicones: []conf.Silicone{{SizeMm: 6}}},
+		DesignRules: conf.DesignRules{MinSpacingMm: 5},
+	}
+	const design = `<svg><g id="6MM"><line x1="0" y1="0" x2="100" y2="0"/><line x1="50" y1="20" x2="150" y2="20"/></g></svg>`
+
+	issues, err := CheckDesign("", []byte(design), config, "", svg.Limits{})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, RuleSpacing, issues[0].Rule)
+	assert.Equal(t, 4.0, issues[0].Value)
+	assert.Equal(t, 5.0, issues[0].Limit)
+	assert.InDelta(t, 100, issues[0].X, 1e-9)
+	assert.InDelta(t, 10, issues[0].Y, 1e-9)
+}
+
+func Test_CheckDesign_dxf(t *testing.T) {
+	config := conf.Configuration{
+		Scale:   1000,
+		Pricing: conf.Pricing{Silicones: []conf.Silicone{{SizeMm: 6}}, LEDs: []conf.LED{{Name: "couleur", MinLengthMm: 25}}},
+	}
+	const design = "0\nSECTION\n2\nENTITIES\n0\nLINE\n8\n6MM\n10\n0\n20\n0\n11\n20\n21\n0\n0\nENDSEC\n0\nEOF\n"
+
+	issues, err := CheckDesign("sign.dxf", []byte(design), config, "", svg.Limits{})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, RuleLEDLength, issues[0].Rule)
+	assert.InDelta(t, 20, issues[0].Value, 1e-9)
+}
@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 	"theo303/neon-pricer/conf"
@@ -9,6 +10,12 @@ import (
 
 const (
 	scaleParam       = "scale"
+	cornerPriceParam = "cornerprice"
+	feedPriceParam   = "feedprice"
+	jumperPriceParam = "jumperprice"
+	cablePriceParam  = "cableprice"
+	standoffParam    = "standoffprice"
+	holeParam        = "holeprice"
 	siliconeParam    = "silic"
 	ledParam         = "led"
 	plexiParam       = "plexi"
@@ -24,14 +31,24 @@ func UpdateConfigWithPostForm(config *conf.Configuration, body []byte) (*conf.Co
 		if len(parts) != 2 {
 			return nil, fmt.Errorf("pair %s does not contain 2 parts", pair)
 		}
+		key, err := url.QueryUnescape(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("unescaping %s: %w", parts[0], err)
+		}
 		value, err := strconv.ParseFloat(parts[1], 64)
 		if err != nil {
 			return nil, fmt.Errorf("parsing %s: %w", parts[1], err)
 		}
-		values[parts[0]] = value
+		values[key] = value
 	}
 
 	config.Scale = values[scaleParam]
+	config.CornerPrice = values[cornerPriceParam]
+	config.FeedPointPrice = values[feedPriceParam]
+	config.JumperPricePerMeter = values[jumperPriceParam]
+	config.CablePricePerMeter = values[cablePriceParam]
+	config.StandoffPrice = values[standoffParam]
+	config.HolePrice = values[holeParam]
 	for idx, s := range config.Silicones {
 		config.Silicones[idx].PricePerMeter = values[fmt.Sprintf("%s-%d", siliconeParam, s.SizeMm)]
 	}
@@ -0,0 +1,42 @@
+package usecases
+
+import (
+	"testing"
+
+	"theo303/neon-pricer/conf"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_UpdateConfigWithPostForm(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want func(c *conf.Configuration)
+	}{
+		"jumper price": {
+			body: "scale=1000&jumperprice=1.2",
+			want: func(c *conf.Configuration) { c.JumperPricePerMeter = 1.2 },
+		},
+		"cable price": {
+			body: "scale=1000&cableprice=0.9",
+			want: func(c *conf.Configuration) { c.CablePricePerMeter = 0.9 },
+		},
+		"standoff and hole prices": {
+			body: "scale=1000&standoffprice=1.8&holeprice=0.5",
+			want: func(c *conf.Configuration) {
+				c.StandoffPrice = 1.8
+				c.HolePrice = 0.5
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			want := conf.Configuration{Scale: 1000}
+			tt.want(&want)
+			got, err := UpdateConfigWithPostForm(&conf.Configuration{}, []byte(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, want, *got)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/svg"
+
+	"theo303/neon-pricer/internal/domain"
+)
+
+// GroupDelta compares a group of two versions of a design.
+type GroupDelta struct {
+	ID string `json:"id"`
+	// Added and Removed tell if the group only exists in the new or the old version.
+	Added     bool    `json:"added,omitempty"`
+	Removed   bool    `json:"removed,omitempty"`
+	OldLength float64 `json:"old_length_mm"`
+	NewLength float64 `json:"new_length_mm"`
+	// LengthDifference is NewLength - OldLength.
+	LengthDifference float64 `json:"length_difference_mm"`
+	OldStrokes       int     `json:"old_strokes"`
+	NewStrokes       int     `json:"new_strokes"`
+	OldWidth         float64 `json:"old_width_mm"`
+	NewWidth         float64 `json:"new_width_mm"`
+	OldHeight        float64 `json:"old_height_mm"`
+	NewHeight        float64 `json:"new_height_mm"`
+	OldPrice         float64 `json:"old_price"`
+	NewPrice         float64 `json:"new_price"`
+	// PriceDifference is NewPrice - OldPrice.
+	PriceDifference float64 `json:"price_difference"`
+}
+
+// QuoteDelta compares two quotes of versions of a design, group by group.
+type QuoteDelta struct {
+	Groups   []GroupDelta `json:"groups"`
+	OldTotal float64      `json:"old_total"`
+	NewTotal float64      `json:"new_total"`
+	// Difference is NewTotal - OldTotal.
+	Difference float64 `json:"difference"`
+	// DifferencePercent is the difference relative to OldTotal, 0 if OldTotal is 0.
+	DifferencePercent float64 `json:"difference_percent"`
+}
+
+// CompareQuotes returns the differences between the quotes before and after a change, groups being matched by id
+// regardless of case, as they are priced. The prices of the groups are the prices of one sign, the totals the prices
+// of all signs.
+func CompareQuotes(before, after domain.Quote) QuoteDelta {
+	before.Sizes, before.Prices = upperGroups(before.Sizes), upperGroups(before.Prices)
+	after.Sizes, after.Prices = upperGroups(after.Sizes), upperGroups(after.Prices)
+	var ids []string
+	for _, q := range []domain.Quote{before, after} {
+		for id := range q.Sizes {
+			ids = append(ids, id)
+		}
+		for id := range q.Prices {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	delta := QuoteDelta{
+		Groups:            make([]GroupDelta, 0, len(ids)),
+		OldTotal:          before.Total,
+		NewTotal:          after.Total,
+		Difference:        domain.Round(after.Total - before.Total),
+		DifferencePercent: percent(after.Total-before.Total, before.Total),
+	}
+	for _, id := range ids {
+		_, inOld := before.Sizes[id]
+		_, inNew := after.Sizes[id]
+		group := GroupDelta{
+			ID:         id,
+			Added:      !inOld && inNew,
+			Removed:    inOld && !inNew,
+			OldLength:  domain.Round(before.Sizes[id].Length),
+			NewLength:  domain.Round(after.Sizes[id].Length),
+			OldStrokes: before.Sizes[id].Strokes,
+			NewStrokes: after.Sizes[id].Strokes,
+			OldWidth:   domain.Round(before.Sizes[id].Width),
+			NewWidth:   domain.Round(after.Sizes[id].Width),
+			OldHeight:  domain.Round(before.Sizes[id].Height),
+			NewHeight:  domain.Round(after.Sizes[id].Height),
+			OldPrice:   before.Prices[id].Total(),
+			NewPrice:   after.Prices[id].Total(),
+		}
+		group.LengthDifference = domain.Round(group.NewLength - group.OldLength)
+		group.PriceDifference = domain.Round(group.NewPrice - group.OldPrice)
+		delta.Groups = append(delta.Groups, group)
+	}
+	return delta
+}
+
+// upperGroups returns the values keyed by the upper case group ids, as GetPrice keys the prices.
+func upperGroups[V any](groups map[string]V) map[string]V {
+	upper := make(map[string]V, len(groups))
+	for id, v := range groups {
+		upper[strings.ToUpper(id)] = v
+	}
+	return upper
+}
+
+// DiffDesigns quotes quantity signs of the designs before and after a change, and compares them.
+func DiffDesigns(before, after []byte, config conf.Configuration, plexi string, quantity int, limits svg.Limits) (QuoteDelta, error) {
+	beforeQuote, err := NewQuote("", before, config, plexi, quantity, limits)
+	if err != nil {
+		return QuoteDelta{}, fmt.Errorf("quoting first design: %w", err)
+	}
+	afterQuote, err := NewQuote("", after, config, plexi, quantity, limits)
+	if err != nil {
+		return QuoteDelta{}, fmt.Errorf("quoting second design: %w", err)
+	}
+	return CompareQuotes(beforeQuote, afterQuote), nil
+}
@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"testing"
+
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CompareQuotes(t *testing.T) {
+	before := domain.Quote{
+		Sizes: map[string]domain.Size{
+			"6MM":     {Length: 1000, Width: 200, Height: 50, Strokes: 2},
+			"DECOUPE": {Length: 400, Width: 100, Height: 100},
+		},
+		Prices: domain.Price{
+			"6MM":     {SiliconePrice: 0.7, LEDPrice: 0.85},
+			"DECOUPE": {PlexiPrice: 0.5},
+		},
+		Total: 2.05,
+	}
+	after := domain.Quote{
+		Sizes: map[string]domain.Size{
+			"6MM":  {Length: 1500, Width: 300, Height: 50, Strokes: 3},
+			"12MM": {Length: 1000, Width: 100, Height: 100, Strokes: 1},
+		},
+		Prices: domain.Price{
+			"6MM":  {SiliconePrice: 1.05, LEDPrice: 1.28},
+			"12MM": {SiliconePrice: 1.1, LEDPrice: 0.85},
+		},
+		Total: 4.28,
+	}
+
+	got := CompareQuotes(before, after)
+	assert.Equal(t, QuoteDelta{
+		Groups: []GroupDelta{
+			{
+				ID: "12MM", Added: true,
+				NewLength: 1000, LengthDifference: 1000,
+				NewStrokes: 1, NewWidth: 100, NewHeight: 100,
+				NewPrice: 1.95, PriceDifference: 1.95,
+			},
+			{
+				ID:        "6MM",
+				OldLength: 1000, NewLength: 1500, LengthDifference: 500,
+				OldStrokes: 2, NewStrokes: 3, OldWidth: 200, NewWidth: 300, OldHeight: 50, NewHeight: 50,
+				OldPrice: 1.55, NewPrice: 2.33, PriceDifference: 0.78,
+			},
+			{
+				ID: "DECOUPE", Removed: true,
+				OldLength: 400, LengthDifference: -400,
+				OldWidth: 100, OldHeight: 100,
+				OldPrice: 0.5, PriceDifference: -0.5,
+			},
+		},
+		OldTotal:          2.05,
+		NewTotal:          4.28,
+		Difference:        2.23,
+		DifferencePercent: 108.78,
+	}, got)
+}
+
+func Test_CompareQuotes_groupCase(t *testing.T) {
+	before := domain.Quote{
+		Sizes:  map[string]domain.Size{"6mm": {Length: 1000}},
+		Prices: domain.Price{"6MM": {SiliconePrice: 0.7}},
+	}
+	after := domain.Quote{
+		Sizes:  map[string]domain.Size{"6mm": {Length: 1500}},
+		Prices: domain.Price{"6MM": {SiliconePrice: 1.05}},
+	}
+
+	got := CompareQuotes(before, after)
+	require.Len(t, got.Groups, 1)
+	assert.Equal(t, "6MM", got.Groups[0].ID)
+	assert.False(t, got.Groups[0].Added)
+	assert.Equal(t, 500.0, got.Groups[0].LengthDifference)
+	assert.Equal(t, 0.35, got.Groups[0].PriceDifference)
+}
+
+func Test_DiffDesigns(t *testing.T) {
+	const before = `<svg><g id="6MM"><line x1="0" y1="0" x2="1000" y2="0"/></g></svg>`
+	const after = `<svg><g id="6MM"><path d="M0,0h1000m-1000,100h500"/></g></svg>`
+	config := testConfiguration()
+	config.Scale = 1000
+
+	got, err := DiffDesigns([]byte(before), []byte(after), config, "", 1, svg.Limits{})
+	require.NoError(t, err)
+	require.Len(t, got.Groups, 1)
+	assert.Equal(t, 500.0, got.Groups[0].LengthDifference)
+	assert.Equal(t, 1, got.Groups[0].OldStrokes)
+	assert.Equal(t, 2, got.Groups[0].NewStrokes)
+	assert.Equal(t, 100.0, got.Groups[0].NewHeight)
+	assert.Equal(t, 0.77, got.Difference)
+
+	_, err = DiffDesigns([]byte(before), []byte(`<svg`), config, "", 1, svg.Limits{})
+	assert.ErrorIs(t, err, ErrInvalidDesign)
+}
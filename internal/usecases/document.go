@@ -0,0 +1,38 @@
+package usecases
+
+import (
+	"fmt"
+	"io"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/pdf"
+	"theo303/neon-pricer/internal/svg"
+)
+
+// WriteQuotePDF writes the customer document of the quote, branded with the given company details.
+func WriteQuotePDF(w io.Writer, quote domain.Quote, branding conf.Branding) error {
+	lines, err := QuoteLines(quote)
+	if err != nil {
+		return fmt.Errorf("detailing quote: %w", err)
+	}
+
+	var preview map[string][]svg.Polyline
+	if len(quote.SVG) > 0 {
+		forms, err := retrieveDesign(quote.FileName, quote.SVG, quote.Config, svg.Limits{})
+		if err != nil {
+			return err
+		}
+		preview, err = GetPolylines(forms)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+		}
+	}
+
+	return pdf.WriteQuote(w, pdf.Quote{
+		Quote:    quote,
+		Lines:    lines,
+		Preview:  preview,
+		Branding: branding,
+	})
+}
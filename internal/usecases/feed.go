@@ -0,0 +1,223 @@
+package usecases
+
+import (
+	"math"
+	"slices"
+
+	"theo303/neon-pricer/internal/svg"
+)
+
+// maxOrderStarts is the number of runs the runs are ordered from, ordering them from each run
+// costing the square of the number of runs.
+const maxOrderStarts = 8
+
+// FeedRun is a run of strokes lit by one LED strip, fed at one of its ends.
+type FeedRun struct {
+	// Strokes are the indices of the strokes of the run, in the order the strip goes through them.
+	Strokes []int
+	// Feed is where the strip is fed, End where it ends, Feed too for a closed run.
+	Feed, End svg.Point
+	// points are the vertices of a closed run, it can be fed at any of them.
+	points []svg.Point
+}
+
+// FeedPlan is the order in which the runs of a group are wired, the end of each run being
+// linked to the feed of the next one by a jumper cable hidden behind the backing.
+type FeedPlan struct {
+	Runs []FeedRun
+	// JumperLength is the total length of the jumpers, in svg units.
+	JumperLength float64
+}
+
+// PlanFeeds joins the strokes whose ends are closer than tolerance into runs, and orders the
+// runs to keep the jumpers between them short, going to the nearest run from each of the first runs.
+func PlanFeeds(polylines []svg.Polyline, tolerance float64) FeedPlan {
+	runs := joinStrokes(polylines, tolerance)
+	if len(runs) == 0 {
+		return FeedPlan{}
+	}
+	var best FeedPlan
+	for start := 0; start < min(len(runs), maxOrderStarts); start++ {
+		plan := orderRuns(runs, start)
+		if start == 0 || plan.JumperLength < best.JumperLength {
+			best = plan
+		}
+	}
+	return best
+}
+
+// strokeEnd is one of the ends of an open stroke, last being its end and not its start.
+type strokeEnd struct {
+	stroke int
+	last   bool
+}
+
+// joinStrokes links the closest pairs of ends of open strokes within tolerance, each end being
+// joined once, and returns the chains of strokes linked together.
+func joinStrokes(polylines []svg.Polyline, tolerance float64) []FeedRun {
+	point := func(e strokeEnd) svg.Point {
+		pl := polylines[e.stroke]
+		if e.last {
+			return pl[len(pl)-1]
+		}
+		return pl[0]
+	}
+	var ends []strokeEnd
+	for i, pl := range polylines {
+		if len(pl) < 2 || pl.Closed() {
+			continue
+		}
+		ends = append(ends, strokeEnd{stroke: i}, strokeEnd{stroke: i, last: true})
+	}
+
+	// ends are bucketed in a grid with cells as large as the tolerance, an end being only compared
+	// to the ends of the cells around its own.
+	cell := tolerance
+	if cell <= 0 {
+		cell = 1
+	}
+	cellOf := func(p svg.Point) [2]int {
+		return [2]int{int(math.Floor(p.X / cell)), int(math.Floor(p.Y / cell))}
+	}
+	grid := make(map[[2]int][]int)
+	for i, e := range ends {
+		c := cellOf(point(e))
+		grid[c] = append(grid[c], i)
+	}
+
+	type pair struct {
+		a, b     int
+		distance float64
+	}
+	var pairs []pair
+	for i, a := range ends {
+		c := cellOf(point(a))
+		for x := c[0] - 1; x <= c[0]+1; x++ {
+			for y := c[1] - 1; y <= c[1]+1; y++ {
+				for _, j := range grid[[2]int{x, y}] {
+					b := ends[j]
+					if j <= i || a.stroke == b.stroke {
+						continue
+					}
+					pa, pb := point(a), point(b)
+					if d := math.Hypot(pa.X-pb.X, pa.Y-pb.Y); d <= tolerance {
+						pairs = append(pairs, pair{a: i, b: j, distance: d})
+					}
+				}
+			}
+		}
+	}
+	slices.SortFunc(pairs, func(x, y pair) int {
+		switch {
+		case x.distance < y.distance:
+			return -1
+		case x.distance > y.distance:
+			return 1
+		case x.a != y.a:
+			return x.a - y.a
+		}
+		return x.b - y.b
+	})
+	joined := make(map[strokeEnd]strokeEnd)
+	for _, p := range pairs {
+		a, b := ends[p.a], ends[p.b]
+		if _, ok := joined[a]; ok {
+			continue
+		}
+		if _, ok := joined[b]; ok {
+			continue
+		}
+		joined[a], joined[b] = b, a
+	}
+
+	visited := make([]bool, len(polylines))
+	var runs []FeedRun
+	// follow walks the chain from the start of the end stroke, and returns the strokes met
+	// and the end the walk stopped at.
+	follow := func(from strokeEnd) ([]int, strokeEnd) {
+		var strokes []int
+		for {
+			visited[from.stroke] = true
+			strokes = append(strokes, from.stroke)
+			out := strokeEnd{stroke: from.stroke, last: !from.last}
+			next, ok := joined[out]
+			if !ok || visited[next.stroke] {
+				return strokes, out
+			}
+			from = next
+		}
+	}
+	// open chains start from an end joined to nothing.
+	for _, e := range ends {
+		if _, ok := joined[e]; ok || visited[e.stroke] {
+			continue
+		}
+		strokes, out := follow(e)
+		runs = append(runs, FeedRun{Strokes: strokes, Feed: point(e), End: point(out)})
+	}
+	// the remaining strokes are single closed strokes or chains closed on themselves.
+	for i, pl := range polylines {
+		if visited[i] || len(pl) == 0 {
+			continue
+		}
+		strokes := []int{i}
+		if !pl.Closed() && len(pl) >= 2 {
+			strokes, _ = follow(strokeEnd{stroke: i})
+		}
+		visited[i] = true
+		var points []svg.Point
+		for _, s := range strokes {
+			points = append(points, polylines[s]...)
+		}
+		runs = append(runs, FeedRun{Strokes: strokes, Feed: points[0], End: points[0], points: points})
+	}
+	return runs
+}
+
+// orderRuns chains the runs from the run start, going each time to the run whose feed is the
+// closest to the end of the previous run, open runs being fed at either end.
+func orderRuns(runs []FeedRun, start int) FeedPlan {
+	done := make([]bool, len(runs))
+	plan := FeedPlan{Runs: make([]FeedRun, 0, len(runs))}
+	plan.Runs = append(plan.Runs, runs[start])
+	done[start] = true
+	for len(plan.Runs) < len(runs) {
+		from := plan.Runs[len(plan.Runs)-1].End
+		var next FeedRun
+		nextIdx, nextDistance := -1, math.Inf(1)
+		for i, run := range runs {
+			if done[i] {
+				continue
+			}
+			entered, d := enterRun(run, from)
+			if d < nextDistance {
+				next, nextIdx, nextDistance = entered, i, d
+			}
+		}
+		done[nextIdx] = true
+		plan.Runs = append(plan.Runs, next)
+		plan.JumperLength += nextDistance
+	}
+	return plan
+}
+
+// enterRun returns the run fed at its point closest to from, and the distance to that point.
+func enterRun(run FeedRun, from svg.Point) (FeedRun, float64) {
+	distance := func(p svg.Point) float64 { return math.Hypot(p.X-from.X, p.Y-from.Y) }
+	if run.points != nil {
+		best := math.Inf(1)
+		for _, p := range run.points {
+			if d := distance(p); d < best {
+				best = d
+				run.Feed, run.End = p, p
+			}
+		}
+		return run, best
+	}
+	if distance(run.End) < distance(run.Feed) {
+		strokes := slices.Clone(run.Strokes)
+		slices.Reverse(strokes)
+		return FeedRun{Strokes: strokes, Feed: run.End, End: run.Feed}, distance(run.End)
+	}
+	return run, distance(run.Feed)
+}
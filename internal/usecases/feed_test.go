@@ -0,0 +1,75 @@
+package usecases
+
+import (
+	"testing"
+
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PlanFeeds(t *testing.T) {
+	square := svg.Polyline{{X: 0, Y: 20}, {X: 5, Y: 20}, {X: 5, Y: 25}, {X: 0, Y: 25}, {X: 0, Y: 20}}
+	tests := map[string]struct {
+		polylines   []svg.Polyline
+		tolerance   float64
+		wantStrokes [][]int
+		wantFeeds   []svg.Point
+		wantJumper  float64
+	}{
+		"joined and reversed runs": {
+			polylines: []svg.Polyline{
+				{{X: 0, Y: 0}, {X: 10, Y: 0}},
+				{{X: 10.5, Y: 0}, {X: 20, Y: 0}},
+				{{X: 30, Y: 10}, {X: 20, Y: 10}},
+				square,
+			},
+			tolerance:   1,
+			wantStrokes: [][]int{{2}, {1, 0}, {3}},
+			wantFeeds:   []svg.Point{{X: 30, Y: 10}, {X: 20, Y: 0}, {X: 0, Y: 20}},
+			wantJumper:  30,
+		},
+		"out of tolerance": {
+			polylines: []svg.Polyline{
+				{{X: 0, Y: 0}, {X: 10, Y: 0}},
+				{{X: 12, Y: 0}, {X: 20, Y: 0}},
+			},
+			tolerance:   1,
+			wantStrokes: [][]int{{0}, {1}},
+			wantFeeds:   []svg.Point{{X: 0, Y: 0}, {X: 12, Y: 0}},
+			wantJumper:  2,
+		},
+		"ends in neighbouring cells": {
+			polylines: []svg.Polyline{
+				{{X: -5, Y: -0.1}, {X: 0.9, Y: -0.1}},
+				{{X: 1.1, Y: 0.1}, {X: 5, Y: 0.1}},
+			},
+			tolerance:   1,
+			wantStrokes: [][]int{{0, 1}},
+			wantFeeds:   []svg.Point{{X: -5, Y: -0.1}},
+		},
+		"chain closed on itself": {
+			polylines: []svg.Polyline{
+				{{X: 0, Y: 0}, {X: 10, Y: 0}, {X: 10, Y: 10}},
+				{{X: 10, Y: 10}, {X: 0, Y: 10}, {X: 0, Y: 0}},
+			},
+			wantStrokes: [][]int{{0, 1}},
+			wantFeeds:   []svg.Point{{X: 0, Y: 0}},
+		},
+		"no stroke": {},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			plan := PlanFeeds(tt.polylines, tt.tolerance)
+			var strokes [][]int
+			var feeds []svg.Point
+			for _, run := range plan.Runs {
+				strokes = append(strokes, run.Strokes)
+				feeds = append(feeds, run.Feed)
+			}
+			assert.Equal(t, tt.wantStrokes, strokes)
+			assert.Equal(t, tt.wantFeeds, feeds)
+			assert.InDelta(t, tt.wantJumper, plan.JumperLength, 1e-9)
+		})
+	}
+}
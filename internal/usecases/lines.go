@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"theo303/neon-pricer/internal/domain"
+)
+
+// QuoteLines details the prices of the quote as items with their quantity and unit price,
+// using the configuration the quote was computed with.
+func QuoteLines(quote domain.Quote) ([]domain.QuoteLine, error) {
+	groups := make([]string, 0, len(quote.Sizes))
+	for g := range quote.Sizes {
+		groups = append(groups, g)
+	}
+	slices.Sort(groups)
+
+	pricing := quote.Config.Pricing
+	var lines []domain.QuoteLine
+	for _, group := range groups {
+		size := quote.Sizes[group]
+		id := strings.ToUpper(group)
+		price := quote.Prices[id]
+
+		if id == "DECOUPE" {
+			plexi := getPlexi(pricing.Plexis, quote.Plexi)
+			lines = append(lines, domain.QuoteLine{
+				Group:       group,
+				Description: fmt.Sprintf("Plexi %s", plexi.Name),
+				Quantity:    size.Height / 1000 * size.Width / 1000,
+				Unit:        "m²",
+				UnitPrice:   plexi.PricePerMeterSquare,
+				Amount:      price.PlexiPrice,
+			})
+			if size.Standoffs > 0 {
+				lines = append(lines, domain.QuoteLine{
+					Group:       group,
+					Description: "Standoffs",
+					Quantity:    float64(size.Standoffs),
+					Unit:        "standoff",
+					UnitPrice:   pricing.StandoffPrice,
+					Amount:      price.MountingPrice,
+				})
+			}
+			if holes := size.Standoffs + size.CableHoles; holes > 0 {
+				lines = append(lines, domain.QuoteLine{
+					Group:       group,
+					Description: "Drilling",
+					Quantity:    float64(holes),
+					Unit:        "hole",
+					UnitPrice:   pricing.HolePrice,
+					Amount:      price.DrillingPrice,
+				})
+			}
+			continue
+		}
+
+		siliconeSize, err := getSiliconeSize(id)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving silicone size: %w", err)
+		}
+		if siliconeSize == 0 {
+			continue
+		}
+		siliconePrice, err := getSiliconePricing(pricing.Silicones, siliconeSize)
+		if err != nil {
+			return nil, fmt.Errorf("retrieving silicone price: %w", err)
+		}
+		led := getLED(pricing.LEDs, id)
+		ledDescription := fmt.Sprintf("LED %s", led.Name)
+		if led.CutIntervalMm > 0 {
+			ledDescription += fmt.Sprintf(" (cut every %vmm)", led.CutIntervalMm)
+		}
+		lines = append(lines,
+			domain.QuoteLine{
+				Group:       group,
+				Description: fmt.Sprintf("Silicone %dmm", siliconeSize),
+				Quantity:    size.Length / 1000,
+				Unit:        "m",
+				UnitPrice:   siliconePrice,
+				Amount:      price.SiliconePrice,
+			},
+			domain.QuoteLine{
+				Group:       group,
+				Description: ledDescription,
+				Quantity:    PurchasedLEDLength(size, led) / 1000,
+				Unit:        "m",
+				UnitPrice:   led.PricePerMeter,
+				Amount:      price.LEDPrice,
+			},
+		)
+		if size.Corners > 0 {
+			lines = append(lines, domain.QuoteLine{
+				Group:       group,
+				Description: "Sharp corners",
+				Quantity:    float64(size.Corners),
+				Unit:        "corner",
+				UnitPrice:   pricing.CornerPrice,
+				Amount:      price.LabourPrice,
+			})
+		}
+		if size.FeedPoints > 0 {
+			lines = append(lines, domain.QuoteLine{
+				Group:       group,
+				Description: "Feed points",
+				Quantity:    float64(size.FeedPoints),
+				Unit:        "point",
+				UnitPrice:   pricing.FeedPointPrice,
+				Amount:      price.FeedPrice,
+			})
+		}
+		if size.JumperLength > 0 {
+			lines = append(lines, domain.QuoteLine{
+				Group:       group,
+				Description: "Jumper cable",
+				Quantity:    size.JumperLength / 1000,
+				Unit:        "m",
+				UnitPrice:   pricing.JumperPricePerMeter,
+				Amount:      price.JumperPrice,
+			})
+		}
+		if size.CableLength > 0 {
+			lines = append(lines, domain.QuoteLine{
+				Group:       group,
+				Description: "Feed cable",
+				Quantity:    size.CableLength / 1000,
+				Unit:        "m",
+				UnitPrice:   pricing.CablePricePerMeter,
+				Amount:      price.CablePrice,
+			})
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,64 @@
+package usecases
+
+import (
+	"testing"
+
+	"theo303/neon-pricer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_QuoteLines(t *testing.T) {
+	config := testConfiguration()
+	config.CornerPrice = 2.5
+	config.FeedPointPrice = 4
+	config.JumperPricePerMeter = 1.2
+	config.CablePricePerMeter = 0.9
+	config.StandoffPrice = 1.8
+	config.HolePrice = 0.5
+	sizes := map[string]domain.Size{
+		"6MM":     {Length: 2000, Corners: 4, FeedPoints: 2, JumperLength: 250, CableLength: 1500},
+		"DECOUPE": {Width: 1000, Height: 500, Standoffs: 4, CableHoles: 2},
+	}
+	prices, err := GetPrice(config.Pricing, sizes, "miroir")
+	require.NoError(t, err)
+
+	lines, err := QuoteLines(domain.Quote{Plexi: "miroir", Config: config, Sizes: sizes, Prices: prices})
+	require.NoError(t, err)
+	assert.Equal(t, []domain.QuoteLine{
+		{Group: "6MM", Description: "Silicone 6mm", Quantity: 2, Unit: "m", UnitPrice: 0.7, Amount: 1.4},
+		{Group: "6MM", Description: "LED couleur", Quantity: 2, Unit: "m", UnitPrice: 0.85, Amount: 1.7},
+		{Group: "6MM", Description: "Sharp corners", Quantity: 4, Unit: "corner", UnitPrice: 2.5, Amount: 10},
+		{Group: "6MM", Description: "Feed points", Quantity: 2, Unit: "point", UnitPrice: 4, Amount: 8},
+		{Group: "6MM", Description: "Jumper cable", Quantity: 0.25, Unit: "m", UnitPrice: 1.2, Amount: 0.3},
+		{Group: "6MM", Description: "Feed cable", Quantity: 1.5, Unit: "m", UnitPrice: 0.9, Amount: 1.35},
+		{Group: "DECOUPE", Description: "Plexi incolore", Quantity: 0.5, Unit: "m²", UnitPrice: 50, Amount: 25},
+		{Group: "DECOUPE", Description: "Standoffs", Quantity: 4, Unit: "standoff", UnitPrice: 1.8, Amount: 7.2},
+		{Group: "DECOUPE", Description: "Drilling", Quantity: 6, Unit: "hole", UnitPrice: 0.5, Amount: 3},
+	}, lines)
+
+	var total float64
+	for _, l := range lines {
+		total += l.Amount
+	}
+	assert.Equal(t, prices.Total(), domain.Round(total))
+}
+
+func Test_QuoteLines_cutInterval(t *testing.T) {
+	config := testConfiguration()
+	config.LEDs[0].CutIntervalMm = 25
+	sizes := map[string]domain.Size{
+		"6MM": {Length: 1990, StrokeLengths: []float64{1000, 990}},
+	}
+	prices, err := GetPrice(config.Pricing, sizes, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1.7, prices["6MM"].LEDPrice)
+
+	lines, err := QuoteLines(domain.Quote{Config: config, Sizes: sizes, Prices: prices})
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Equal(t, domain.QuoteLine{
+		Group: "6MM", Description: "LED couleur (cut every 25mm)", Quantity: 2, Unit: "m", UnitPrice: 0.85, Amount: 1.7,
+	}, lines[1])
+}
@@ -0,0 +1,183 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/render"
+	"theo303/neon-pricer/internal/svg"
+)
+
+// ErrNoBacking is returned when a design has no DECOUPE group to cut the backing from.
+var ErrNoBacking = errors.New("no DECOUPE group to cut the backing from")
+
+// Kinds of holes drilled in the backing.
+const (
+	HoleStandoff = "standoff"
+	HoleCable    = "cable"
+)
+
+// Hole is a hole drilled in the backing, in svg units.
+type Hole struct {
+	Kind     string
+	Center   svg.Point
+	Diameter float64
+}
+
+// PlanMounting proposes the holes drilled in the DECOUPE backing: one for a standoff near each
+// corner of its bounds, at least the mounting margin inside its outline, and one under the feed
+// point of each run of strokes for its cable. Designs without DECOUPE group have no hole.
+func PlanMounting(polylines map[string][]svg.Polyline, wiring WiringPlan, config conf.Configuration) []Hole {
+	var outlines []svg.Polyline
+	for _, pl := range polylines["DECOUPE"] {
+		if pl.Closed() {
+			outlines = append(outlines, pl)
+		}
+	}
+	if len(outlines) == 0 {
+		return nil
+	}
+	toSVG := config.Scale / 1000
+	margin := config.Mounting.MarginMm * toSVG
+
+	bounds := outlines[0].Bounds()
+	for _, pl := range outlines[1:] {
+		bounds = bounds.Expand(pl.Bounds())
+	}
+	lo, hi := bounds.Min(), bounds.Max()
+	center := svg.Point{X: (lo.X + hi.X) / 2, Y: (lo.Y + hi.Y) / 2}
+	// the holes are moved from the corners towards the center by steps of half the margin,
+	// until they are far enough inside the outline.
+	step := max(margin/2, toSVG)
+
+	var holes []Hole
+	add := func(h Hole) {
+		for _, other := range holes {
+			if math.Hypot(h.Center.X-other.Center.X, h.Center.Y-other.Center.Y) < (h.Diameter+other.Diameter)/2 {
+				return
+			}
+		}
+		holes = append(holes, h)
+	}
+	for _, corner := range []svg.Point{lo, {X: hi.X, Y: lo.Y}, hi, {X: lo.X, Y: hi.Y}} {
+		dx, dy := math.Copysign(1, center.X-corner.X), math.Copysign(1, center.Y-corner.Y)
+		for inset := margin; inset <= math.Min(bounds.Width(), bounds.Height())/2; inset += step {
+			p := svg.Point{X: corner.X + dx*inset, Y: corner.Y + dy*inset}
+			if insideOutlines(outlines, p) && distanceToOutlines(outlines, p) >= margin-1e-9 {
+				add(Hole{Kind: HoleStandoff, Center: p, Diameter: config.Mounting.StandoffHoleMm * toSVG})
+				break
+			}
+		}
+	}
+	for _, g := range wiring.Groups {
+		for _, run := range g.Plan.Runs {
+			if insideOutlines(outlines, run.Feed) {
+				add(Hole{Kind: HoleCable, Center: run.Feed, Diameter: config.Mounting.CableHoleMm * toSVG})
+			}
+		}
+	}
+	return holes
+}
+
+// insideOutlines reports whether p is inside the shape cut along the outlines,
+// the outlines inside another one being holes in it.
+func insideOutlines(outlines []svg.Polyline, p svg.Point) bool {
+	inside := false
+	for _, pl := range outlines {
+		if pl.Contains(p) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func distanceToOutlines(outlines []svg.Polyline, p svg.Point) float64 {
+	distance := math.Inf(1)
+	for _, pl := range outlines {
+		for i := 1; i < len(pl); i++ {
+			d, _ := svg.SegmentsDistance(p, p, pl[i-1], pl[i])
+			distance = min(distance, d)
+		}
+	}
+	return distance
+}
+
+// Formats of the cut files.
+const (
+	CutFormatSVG = "svg"
+	CutFormatDXF = "dxf"
+)
+
+// CutFormats lists the formats the cut files are written in.
+var CutFormats = []string{CutFormatSVG, CutFormatDXF}
+
+// defaultCutTolerance is the tolerance in mm the curves are flattened to if none is configured.
+const defaultCutTolerance = 0.05
+
+// WriteCutFile writes the cut file of the plexi backing of the design file in millimetres, as a svg or a dxf:
+// the outline of its DECOUPE group and the holes drilled in it. Its arcs and curves are kept if configured,
+// else flattened to the configured tolerance. It returns the holes drilled.
+func WriteCutFile(w io.Writer, fileName string, file []byte, config conf.Configuration, format string) ([]Hole, error) {
+	write, ok := map[string]func(io.Writer, render.CutSheet) error{
+		CutFormatSVG: render.CutFile,
+		CutFormatDXF: render.CutDXF,
+	}[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown cut file format %q, expected one of %s", format, strings.Join(CutFormats, ", "))
+	}
+	forms, err := retrieveDesign(fileName, file, config, svg.Limits{})
+	if err != nil {
+		return nil, err
+	}
+	polylines, err := GetPolylines(forms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+	}
+	bounds, err := GetBounds(forms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+	}
+	backing, ok := bounds["DECOUPE"]
+	if !ok {
+		return nil, ErrNoBacking
+	}
+	wiring, err := PlanWiring(polylines, bounds, config)
+	if err != nil {
+		return nil, err
+	}
+	holes := PlanMounting(polylines, wiring, config)
+
+	// the cut file starts at the top left corner of the backing.
+	toMm := 1000 / config.Scale
+	origin := backing.Min()
+	tolerance := config.CutFile.ToleranceMm
+	if tolerance == 0 {
+		tolerance = defaultCutTolerance
+	}
+	sheet := render.CutSheet{WidthMm: backing.Width() * toMm, HeightMm: backing.Height() * toMm, ToleranceMm: tolerance}
+	for i, form := range forms["DECOUPE"] {
+		contours, err := svg.Contours(form)
+		if err != nil {
+			return nil, fmt.Errorf("%w: outlining form n %d of group DECOUPE: %w", ErrInvalidDesign, i, err)
+		}
+		for _, c := range contours {
+			c = c.Transform(origin, toMm)
+			if !config.CutFile.KeepCurves {
+				c = svg.LineContour(c.Flatten(tolerance))
+			}
+			sheet.Outlines = append(sheet.Outlines, c)
+		}
+	}
+	for _, h := range holes {
+		center := svg.Point{X: (h.Center.X - origin.X) * toMm, Y: (h.Center.Y - origin.Y) * toMm}
+		sheet.Holes = append(sheet.Holes, render.CutHole{Center: center, DiameterMm: h.Diameter * toMm})
+	}
+	if err := write(w, sheet); err != nil {
+		return nil, fmt.Errorf("writing cut file: %w", err)
+	}
+	return holes, nil
+}
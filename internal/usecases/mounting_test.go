@@ -0,0 +1,152 @@
+package usecases
+
+import (
+	"bytes"
+	"testing"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PlanMounting(t *testing.T) {
+	rectangle := svg.Polyline{{X: 0, Y: 0}, {X: 200, Y: 0}, {X: 200, Y: 100}, {X: 0, Y: 100}, {X: 0, Y: 0}}
+	lShape := svg.Polyline{{X: 0, Y: 0}, {X: 200, Y: 0}, {X: 200, Y: 50}, {X: 100, Y: 50}, {X: 100, Y: 100}, {X: 0, Y: 100}, {X: 0, Y: 0}}
+	wiring := WiringPlan{Groups: []GroupWiring{{
+		ID: "6MM",
+		Plan: FeedPlan{Runs: []FeedRun{
+			{Feed: svg.Point{X: 50, Y: 50}},
+			{Feed: svg.Point{X: 51, Y: 50}},
+			{Feed: svg.Point{X: 300, Y: 50}},
+		}},
+	}}}
+	tests := map[string]struct {
+		decoupe []svg.Polyline
+		want    []Hole
+	}{
+		"rectangle": {
+			decoupe: []svg.Polyline{rectangle},
+			want: []Hole{
+				{Kind: HoleStandoff, Center: svg.Point{X: 20, Y: 20}, Diameter: 8},
+				{Kind: HoleStandoff, Center: svg.Point{X: 180, Y: 20}, Diameter: 8},
+				{Kind: HoleStandoff, Center: svg.Point{X: 180, Y: 80}, Diameter: 8},
+				{Kind: HoleStandoff, Center: svg.Point{X: 20, Y: 80}, Diameter: 8},
+				{Kind: HoleCable, Center: svg.Point{X: 50, Y: 50}, Diameter: 6},
+			},
+		},
+		"corner outside the outline": {
+			decoupe: []svg.Polyline{lShape},
+			want: []Hole{
+				{Kind: HoleStandoff, Center: svg.Point{X: 20, Y: 20}, Diameter: 8},
+				{Kind: HoleStandoff, Center: svg.Point{X: 180, Y: 20}, Diameter: 8},
+				{Kind: HoleStandoff, Center: svg.Point{X: 20, Y: 80}, Diameter: 8},
+				{Kind: HoleCable, Center: svg.Point{X: 50, Y: 50}, Diameter: 6},
+			},
+		},
+		"no backing": {},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := testConfiguration()
+			config.Scale = 1000
+			config.Mounting.MarginMm = 20
+			config.Mounting.StandoffHoleMm = 8
+			config.Mounting.CableHoleMm = 6
+			polylines := map[string][]svg.Polyline{"DECOUPE": tt.decoupe}
+			assert.Equal(t, tt.want, PlanMounting(polylines, wiring, config))
+		})
+	}
+}
+
+func Test_WriteCutFile(t *testing.T) {
+	config := testConfiguration()
+	config.Scale = 1000
+	config.Mounting.MarginMm = 10
+	config.Mounting.StandoffHoleMm = 8
+	config.Mounting.CableHoleMm = 6
+	file := []byte(`<svg><g id="6MM"><line x1="60" y1="65" x2="140" y2="65"/></g>` +
+		`<g id="DECOUPE"><rect x="50" y="40" width="100" height="50"/></g></svg>`)
+
+	var buf bytes.Buffer
+	holes, err := WriteCutFile(&buf, "", file, config, CutFormatSVG)
+	require.NoError(t, err)
+	assert.Len(t, holes, 5)
+	got := buf.String()
+	assert.Contains(t, got, `width="100mm" height="50mm" viewBox="0 0 100 50"`)
+	assert.Contains(t, got, `<polygon points="0,0 100,0 100,50 0,50"/>`)
+	assert.Contains(t, got, `<circle cx="10" cy="10" r="4"/>`)
+	assert.Contains(t, got, `<circle cx="10" cy="25" r="3"/>`, "the cable hole is under the feed point")
+
+	_, err = WriteCutFile(&buf, "", []byte(`<svg><g id="6MM"><line x1="0" y1="0" x2="10" y2="0"/></g></svg>`), config, CutFormatSVG)
+	assert.ErrorIs(t, err, ErrNoBacking)
+
+	buf.Reset()
+	lower := bytes.Replace(file, []byte(`id="DECOUPE"`), []byte(`id="Decoupe"`), 1)
+	holes, err = WriteCutFile(&buf, "", lower, config, CutFormatSVG)
+	require.NoError(t, err, "the backing group is recognised in any case")
+	assert.Len(t, holes, 5)
+}
+
+func Test_WriteCutFile_formats(t *testing.T) {
+	file := []byte(`<svg><g id="6MM"><line x1="20" y1="25" x2="80" y2="25"/></g><g id="DECOUPE">` +
+		`<path d="M10,0 H90 A10,10,0,0,1,100,10 V40 A10,10,0,0,1,90,50 H10 A10,10,0,0,1,0,40 V10 A10,10,0,0,1,10,0 Z"/>` +
+		`</g></svg>`)
+	tests := map[string]struct {
+		format      string
+		keepCurves  bool
+		want        []string
+		wantMissing []string
+		wantErr     bool
+	}{
+		"svg with arcs": {
+			format:     CutFormatSVG,
+			keepCurves: true,
+			want:       []string{`<path d="M10,0 L90,0 A10,10,0,0,1,100,10 L100,40 A10,10,0,0,1,90,50`, ` Z"/>`},
+		},
+		"flattened svg": {
+			format:      CutFormatSVG,
+			want:        []string{`<polygon points="10,0 90,0 `},
+			wantMissing: []string{`<path`},
+		},
+		"dxf with bulges": {
+			format:     CutFormatDXF,
+			keepCurves: true,
+			want: []string{"AC1009", "0\nPOLYLINE\n8\nCUT\n", "70\n1\n", "10\n90\n20\n50\n30\n0\n42\n-0.414214\n",
+				"0\nCIRCLE\n8\nHOLES\n", "0\nEOF\n"},
+		},
+		"flattened dxf": {
+			format:      CutFormatDXF,
+			want:        []string{"0\nPOLYLINE\n8\nCUT\n"},
+			wantMissing: []string{"\n42\n"},
+		},
+		"unknown format": {
+			format:  "pdf",
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := testConfiguration()
+			config.Scale = 1000
+			config.Mounting.MarginMm = 10
+			config.Mounting.StandoffHoleMm = 8
+			config.CutFile = conf.CutFile{ToleranceMm: 0.05, KeepCurves: tt.keepCurves}
+
+			var buf bytes.Buffer
+			_, err := WriteCutFile(&buf, "", file, config, tt.format)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, buf.String(), want)
+			}
+			for _, missing := range tt.wantMissing {
+				assert.NotContains(t, buf.String(), missing)
+			}
+		})
+	}
+}
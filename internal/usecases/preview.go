@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"fmt"
+	"image/png"
+	"io"
+	"slices"
+	"strings"
+
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/render"
+	"theo303/neon-pricer/internal/svg"
+)
+
+// DefaultPreviewWidth is the width in pixels of the preview images.
+const DefaultPreviewWidth = 1200
+
+// PreviewOptions changes how the preview of a design is rendered.
+type PreviewOptions struct {
+	// Width is the width of the image in pixels.
+	Width int
+	// Dark draws the design on a dark background instead of its plexi backing.
+	Dark bool
+}
+
+// WritePreview renders the design of the quote as a png image of its lit neons,
+// using the colors of the LEDs and plexi of the quote configuration.
+func WritePreview(w io.Writer, quote domain.Quote, options PreviewOptions) error {
+	forms, err := retrieveDesign(quote.FileName, quote.SVG, quote.Config, svg.Limits{})
+	if err != nil {
+		return err
+	}
+	design, err := previewDesign(quote, forms, options)
+	if err != nil {
+		return err
+	}
+	if options.Width == 0 {
+		options.Width = DefaultPreviewWidth
+	}
+	img, err := render.Preview(design, options.Width)
+	if err != nil {
+		return fmt.Errorf("rendering preview: %w", err)
+	}
+	return png.Encode(w, img)
+}
+
+func previewDesign(quote domain.Quote, forms map[string][]svg.Form, options PreviewOptions) (render.Design, error) {
+	polylines, err := GetPolylines(forms)
+	if err != nil {
+		return render.Design{}, fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+	}
+	config := quote.Config
+	design := render.Design{UnitsPerMm: config.Scale / 1000}
+
+	groups := make([]string, 0, len(polylines))
+	for g := range polylines {
+		groups = append(groups, g)
+	}
+	slices.Sort(groups)
+	for _, group := range groups {
+		id := strings.ToUpper(group)
+		if id == "DECOUPE" {
+			if options.Dark {
+				continue
+			}
+			for _, pl := range polylines[group] {
+				if pl.Closed() {
+					design.Backing = append(design.Backing, pl)
+				}
+			}
+			design.BackingColor = render.ClearPlexi
+			if plexi := getPlexi(config.Plexis, quote.Plexi); plexi.Color != "" {
+				if design.BackingColor, err = render.ParseHexColor(plexi.Color); err != nil {
+					return render.Design{}, err
+				}
+			}
+			continue
+		}
+
+		siliconeSize, err := getSiliconeSize(id)
+		if err != nil {
+			return render.Design{}, fmt.Errorf("retrieving silicone size: %w", err)
+		}
+		if siliconeSize == 0 {
+			continue
+		}
+		layer := render.Layer{
+			Polylines: polylines[group],
+			Color:     render.WarmWhite,
+			Thickness: float64(siliconeSize) * design.UnitsPerMm,
+		}
+		if led := getLED(config.LEDs, id); led.Color != "" {
+			if layer.Color, err = render.ParseHexColor(led.Color); err != nil {
+				return render.Design{}, err
+			}
+		}
+		design.Layers = append(design.Layers, layer)
+	}
+	return design, nil
+}
@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"fmt"
+	"math"
 	"regexp"
 	"slices"
 	"strconv"
@@ -14,28 +15,22 @@ const defaultSiliconeSize = 12
 
 var mmRegexp = regexp.MustCompile(`(?i)(\d+)MM`)
 
-type LayerPrice struct {
-	SiliconePrice float64
-	LEDPrice      float64
-	PlexiPrice    float64
-}
-
-type Price map[string]LayerPrice
-
-func GetPrice(config conf.Pricing, sizes map[string]domain.Size, plexi string) (Price, error) {
-	price := make(map[string]LayerPrice)
+func GetPrice(config conf.Pricing, sizes map[string]domain.Size, plexi string) (domain.Price, error) {
+	price := make(domain.Price)
 	for id, size := range sizes {
 		id = strings.ToUpper(id)
 		if id == "DECOUPE" {
 			area := size.Height / 1000 * size.Width / 1000
-			price[id] = LayerPrice{
-				PlexiPrice: domain.Round(getPlexiPricing(config.Plexis, plexi) * area),
+			price[id] = domain.LayerPrice{
+				PlexiPrice:    domain.Round(getPlexiPricing(config.Plexis, plexi) * area),
+				MountingPrice: domain.Round(config.StandoffPrice * float64(size.Standoffs)),
+				DrillingPrice: domain.Round(config.HolePrice * float64(size.Standoffs+size.CableHoles)),
 			}
 		}
 
 		siliconeSize, err := getSiliconeSize(id)
 		if err != nil {
-			return Price{}, fmt.Errorf("retrieving silicone size: %w", err)
+			return nil, fmt.Errorf("retrieving silicone size: %w", err)
 		}
 		if siliconeSize == 0 {
 			continue
@@ -43,11 +38,16 @@ func GetPrice(config conf.Pricing, sizes map[string]domain.Size, plexi string) (
 
 		siliconePrice, err := getSiliconePricing(config.Silicones, siliconeSize)
 		if err != nil {
-			return Price{}, fmt.Errorf("retrieving silicone price: %w", err)
+			return nil, fmt.Errorf("retrieving silicone price: %w", err)
 		}
-		price[id] = LayerPrice{
+		led := getLED(config.LEDs, id)
+		price[id] = domain.LayerPrice{
 			SiliconePrice: domain.Round(siliconePrice * size.Length / 1000),
-			LEDPrice:      domain.Round(getLEDPricing(config.LEDs, id) * size.Length / 1000),
+			LEDPrice:      domain.Round(led.PricePerMeter * PurchasedLEDLength(size, led) / 1000),
+			LabourPrice:   domain.Round(config.CornerPrice * float64(size.Corners)),
+			FeedPrice:     domain.Round(config.FeedPointPrice * float64(size.FeedPoints)),
+			JumperPrice:   domain.Round(config.JumperPricePerMeter * size.JumperLength / 1000),
+			CablePrice:    domain.Round(config.CablePricePerMeter * size.CableLength / 1000),
 		}
 	}
 	return price, nil
@@ -68,37 +68,99 @@ func getSiliconeSize(id string) (int, error) {
 }
 
 func getSiliconePricing(pricings []conf.Silicone, size int) (float64, error) {
+	silicone, err := getSilicone(pricings, size)
+	if err != nil {
+		return 0, err
+	}
+	return silicone.PricePerMeter, nil
+}
+
+func getSilicone(pricings []conf.Silicone, size int) (conf.Silicone, error) {
 	for _, pricingSilicone := range pricings {
 		if pricingSilicone.SizeMm == size {
-			return pricingSilicone.PricePerMeter, nil
+			return pricingSilicone, nil
+		}
+	}
+	return conf.Silicone{}, fmt.Errorf("no pricing could be found for size %dMM", size)
+}
+
+// PlanLEDStrips returns the strip of LED to buy for each stroke of a group, as long as the stroke
+// rounded up to the next cut point, and at least as long as the shortest strip.
+func PlanLEDStrips(size domain.Size, led conf.LED) []domain.StripCut {
+	strips := make([]domain.StripCut, len(size.StrokeLengths))
+	for i, length := range size.StrokeLengths {
+		purchased := length
+		if led.CutIntervalMm > 0 {
+			// the tolerance keeps strokes drawn at a cut point from being rounded up to the next one.
+			purchased = math.Ceil(length/led.CutIntervalMm-1e-9) * led.CutIntervalMm
+		}
+		purchased = max(purchased, led.MinLengthMm)
+		strips[i] = domain.StripCut{
+			LengthMm:    length,
+			PurchasedMm: purchased,
+			WasteMm:     purchased - length,
+		}
+	}
+	return strips
+}
+
+// PurchasedLEDLength returns the length in mm of LED strip to buy for a group,
+// its exact length if the lengths of its strokes are unknown.
+func PurchasedLEDLength(size domain.Size, led conf.LED) float64 {
+	if len(size.StrokeLengths) == 0 {
+		return size.Length
+	}
+	var length float64
+	for _, strip := range PlanLEDStrips(size, led) {
+		length += strip.PurchasedMm
+	}
+	return length
+}
+
+// GetLEDStrips returns the strips of LED to buy for the strokes of each group with a silicone.
+func GetLEDStrips(leds []conf.LED, sizes map[string]domain.Size) (map[string][]domain.StripCut, error) {
+	strips := make(map[string][]domain.StripCut)
+	for id, size := range sizes {
+		siliconeSize, err := getSiliconeSize(strings.ToUpper(id))
+		if err != nil {
+			return nil, fmt.Errorf("retrieving silicone size: %w", err)
+		}
+		if siliconeSize == 0 {
+			continue
 		}
+		strips[id] = PlanLEDStrips(size, getLED(leds, strings.ToUpper(id)))
 	}
-	return 0, fmt.Errorf("no pricing could be found for size %dMM", size)
+	return strips, nil
 }
 
-func getLEDPricing(pricings []conf.LED, id string) float64 {
-	var defaultPricing float64
+// getLED returns the LED named after the group id, or the default couleur LED.
+func getLED(pricings []conf.LED, id string) conf.LED {
+	var defaultLED conf.LED
 	for _, pricingLed := range pricings {
 		switch pricingLed.Name {
 		case id:
-			return pricingLed.PricePerMeter
+			return pricingLed
 		case "couleur":
-			defaultPricing = pricingLed.PricePerMeter
+			defaultLED = pricingLed
 		}
 	}
-	return defaultPricing
+	return defaultLED
 }
 
 func getPlexiPricing(pricings []conf.Plexi, name string) float64 {
-	var defaultPricing float64
-	fmt.Println(name)
+	return getPlexi(pricings, name).PricePerMeterSquare
+}
+
+// getPlexi returns the plexi with the given name, or the default incolore plexi.
+func getPlexi(pricings []conf.Plexi, name string) conf.Plexi {
+	var defaultPlexi conf.Plexi
 	for _, pricingPlexi := range pricings {
 		switch pricingPlexi.Name {
 		case name:
-			return pricingPlexi.PricePerMeterSquare
+			return pricingPlexi
 		case "incolore":
-			defaultPricing = pricingPlexi.PricePerMeterSquare
+			defaultPlexi = pricingPlexi
 		}
 	}
-	return defaultPricing
+	return defaultPlexi
 }
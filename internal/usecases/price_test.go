@@ -3,6 +3,7 @@ package usecases
 import (
 	"testing"
 	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -54,3 +55,41 @@ func Test_getSiliconePricing(t *testing.T) {
 		})
 	}
 }
+
+func Test_PlanLEDStrips(t *testing.T) {
+	tests := map[string]struct {
+		size domain.Size
+		led  conf.LED
+		want []domain.StripCut
+	}{
+		"cut anywhere": {
+			size: domain.Size{StrokeLengths: []float64{112.5}},
+			led:  conf.LED{Name: "couleur"},
+			want: []domain.StripCut{{LengthMm: 112.5, PurchasedMm: 112.5}},
+		},
+		"cut interval": {
+			size: domain.Size{StrokeLengths: []float64{112.5, 50}},
+			led:  conf.LED{Name: "couleur", CutIntervalMm: 25},
+			want: []domain.StripCut{
+				{LengthMm: 112.5, PurchasedMm: 125, WasteMm: 12.5},
+				{LengthMm: 50, PurchasedMm: 50},
+			},
+		},
+		"shortest strip": {
+			size: domain.Size{StrokeLengths: []float64{20}},
+			led:  conf.LED{Name: "RGB", CutIntervalMm: 25, MinLengthMm: 50},
+			want: []domain.StripCut{{LengthMm: 20, PurchasedMm: 50, WasteMm: 30}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, PlanLEDStrips(tt.size, tt.led))
+		})
+	}
+}
+
+func Test_PurchasedLEDLength(t *testing.T) {
+	led := conf.LED{Name: "couleur", CutIntervalMm: 25}
+	assert.Equal(t, 175.0, PurchasedLEDLength(domain.Size{Length: 162.5, StrokeLengths: []float64{112.5, 50}}, led))
+	assert.Equal(t, 162.5, PurchasedLEDLength(domain.Size{Length: 162.5}, led))
+}
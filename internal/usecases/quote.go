@@ -0,0 +1,54 @@
+package usecases
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+)
+
+var (
+	// ErrInvalidDesign is returned when a design cannot be parsed or measured.
+	ErrInvalidDesign = errors.New("invalid design")
+	// ErrInvalidQuantity is returned when the number of signs of a quote is not positive.
+	ErrInvalidQuantity = errors.New("invalid quantity")
+)
+
+// NewQuote measures and prices quantity signs of the svg file, and returns the result as a quote
+// holding everything needed to consult it later.
+func NewQuote(fileName string, file []byte, config conf.Configuration, plexi string, quantity int, limits svg.Limits) (domain.Quote, error) {
+	if quantity < 1 {
+		return domain.Quote{}, fmt.Errorf("%w: %d signs", ErrInvalidQuantity, quantity)
+	}
+	forms, err := retrieveDesign(fileName, file, config, limits)
+	if err != nil {
+		return domain.Quote{}, err
+	}
+	sizes, err := measureDesign(forms, config)
+	if err != nil {
+		return domain.Quote{}, fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+	}
+	prices, err := GetPrice(config.Pricing, sizes, plexi)
+	if err != nil {
+		return domain.Quote{}, fmt.Errorf("computing prices: %w", err)
+	}
+
+	hash := sha256.Sum256(file)
+	return domain.Quote{
+		CreatedAt: time.Now(),
+		FileName:  fileName,
+		Hash:      hex.EncodeToString(hash[:]),
+		Plexi:     plexi,
+		Config:    config.Clone(),
+		Sizes:     sizes,
+		Prices:    prices,
+		Quantity:  quantity,
+		Total:     domain.Round(prices.Total() * float64(quantity)),
+		SVG:       file,
+	}, nil
+}
@@ -0,0 +1,106 @@
+package usecases
+
+import (
+	"testing"
+
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewQuote(t *testing.T) {
+	const design = `<svg><g id="6MM"><line x1="0" y1="0" x2="1000" y2="0"/></g></svg>`
+	config := testConfiguration()
+	config.Scale = 1000
+
+	tests := map[string]struct {
+		file      string
+		quantity  int
+		wantTotal float64
+		wantErr   error
+	}{
+		"one sign": {
+			file:      design,
+			quantity:  1,
+			wantTotal: 1.55,
+		},
+		"several signs": {
+			file:      design,
+			quantity:  3,
+			wantTotal: 4.65,
+		},
+		"no sign": {
+			file:     design,
+			quantity: 0,
+			wantErr:  ErrInvalidQuantity,
+		},
+		"invalid design": {
+			file:     `<svg><g id="6MM"><circle r="x"/></g></svg>`,
+			quantity: 1,
+			wantErr:  ErrInvalidDesign,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewQuote("sign.svg", []byte(tt.file), config, "", tt.quantity, svg.Limits{})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.quantity, got.Copies())
+			assert.Equal(t, tt.wantTotal, got.Total)
+			assert.Equal(t, 1.55, got.Prices.Total())
+		})
+	}
+}
+
+func Test_NewQuote_corners(t *testing.T) {
+	config := testConfiguration()
+	config.Scale = 1000
+	config.CornerPrice = 2
+	config.DesignRules.CornerAngleDeg = 45
+
+	got, err := NewQuote("sign.svg", []byte(`<svg><g id="6MM"><rect width="1000" height="1000"/></g></svg>`),
+		config, "", 1, svg.Limits{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, got.Sizes["6MM"].Corners)
+	assert.Equal(t, 8.0, got.Prices["6MM"].LabourPrice)
+	assert.Equal(t, 14.2, got.Total)
+}
+
+func Test_NewQuote_dxf(t *testing.T) {
+	// drawing returns a DXF file with a line on the 6MM layer, in the units of $INSUNITS.
+	drawing := func(units, length string) string {
+		return "0\nSECTION\n2\nHEADER\n9\n$INSUNITS\n70\n" + units + "\n0\nENDSEC\n" +
+			"0\nSECTION\n2\nENTITIES\n0\nLINE\n8\n6MM\n10\n0\n20\n0\n11\n" + length + "\n21\n0\n0\nENDSEC\n0\nEOF\n"
+	}
+	config := testConfiguration()
+	config.Scale = 1000
+
+	tests := map[string]struct {
+		fileName string
+		file     string
+	}{
+		"millimetres": {
+			fileName: "sign.dxf",
+			file:     drawing("4", "1000"),
+		},
+		"centimetres": {
+			fileName: "sign.DXF",
+			file:     drawing("5", "100"),
+		},
+		"recognised by its content": {
+			file: drawing("6", "1"),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewQuote(tt.fileName, []byte(tt.file), config, "", 1, svg.Limits{})
+			require.NoError(t, err)
+			assert.InDelta(t, 1000, got.Sizes["6MM"].Length, 1e-9)
+			assert.Equal(t, 1.55, got.Total)
+		})
+	}
+}
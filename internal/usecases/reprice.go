@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/svg"
+)
+
+// QuoteRepricing compares the prices of a quote with the ones of another configuration.
+type QuoteRepricing struct {
+	QuoteID   string       `json:"quote_id"`
+	FileName  string       `json:"file_name"`
+	OldPrices domain.Price `json:"old_prices"`
+	NewPrices domain.Price `json:"new_prices"`
+	OldTotal  float64      `json:"old_total"`
+	NewTotal  float64      `json:"new_total"`
+	// Difference is NewTotal - OldTotal.
+	Difference float64 `json:"difference"`
+	// DifferencePercent is the difference relative to OldTotal, 0 if OldTotal is 0.
+	DifferencePercent float64 `json:"difference_percent"`
+}
+
+// SkippedQuote is a quote that could not be priced again.
+type SkippedQuote struct {
+	QuoteID  string `json:"quote_id"`
+	FileName string `json:"file_name"`
+	Reason   string `json:"reason"`
+}
+
+// RepricingReport holds the repricing of a set of quotes and the aggregated difference.
+type RepricingReport struct {
+	Quotes []QuoteRepricing `json:"quotes"`
+	// Skipped lists the quotes left out of the totals, without sizes nor valid svg file.
+	Skipped           []SkippedQuote `json:"skipped"`
+	OldTotal          float64        `json:"old_total"`
+	NewTotal          float64        `json:"new_total"`
+	Difference        float64        `json:"difference"`
+	DifferencePercent float64        `json:"difference_percent"`
+}
+
+// RepriceQuotes computes the prices of the quotes with config.
+// The sizes stored in the quotes are used, rescaled if the scale changed,
+// the svg file is only parsed again for quotes without sizes, which must be loaded with it.
+// The quotes without sizes nor valid svg file are skipped.
+func RepriceQuotes(quotes []domain.Quote, config conf.Configuration) (RepricingReport, error) {
+	report := RepricingReport{Quotes: []QuoteRepricing{}, Skipped: []SkippedQuote{}}
+	for _, quote := range quotes {
+		sizes, err := repricingSizes(quote, config)
+		if errors.Is(err, errNoSizes) || errors.Is(err, ErrInvalidDesign) {
+			report.Skipped = append(report.Skipped, SkippedQuote{QuoteID: quote.ID, FileName: quote.FileName, Reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return RepricingReport{}, fmt.Errorf("quote %s: %w", quote.ID, err)
+		}
+		prices, err := GetPrice(config.Pricing, sizes, quote.Plexi)
+		if err != nil {
+			return RepricingReport{}, fmt.Errorf("quote %s: computing prices: %w", quote.ID, err)
+		}
+
+		newTotal := domain.Round(prices.Total() * float64(quote.Copies()))
+		report.Quotes = append(report.Quotes, QuoteRepricing{
+			QuoteID:           quote.ID,
+			FileName:          quote.FileName,
+			OldPrices:         quote.Prices,
+			NewPrices:         prices,
+			OldTotal:          quote.Total,
+			NewTotal:          newTotal,
+			Difference:        domain.Round(newTotal - quote.Total),
+			DifferencePercent: percent(newTotal-quote.Total, quote.Total),
+		})
+		report.OldTotal += quote.Total
+		report.NewTotal += newTotal
+	}
+	report.OldTotal = domain.Round(report.OldTotal)
+	report.NewTotal = domain.Round(report.NewTotal)
+	report.Difference = domain.Round(report.NewTotal - report.OldTotal)
+	report.DifferencePercent = percent(report.NewTotal-report.OldTotal, report.OldTotal)
+	return report, nil
+}
+
+// errNoSizes is returned when a quote cannot be measured again.
+var errNoSizes = errors.New("quote has neither sizes nor svg file")
+
+func repricingSizes(quote domain.Quote, config conf.Configuration) (map[string]domain.Size, error) {
+	scale := config.Scale
+	if len(quote.Sizes) == 0 {
+		if len(quote.SVG) == 0 {
+			return nil, errNoSizes
+		}
+		forms, err := retrieveDesign(quote.FileName, quote.SVG, config, svg.Limits{})
+		if err != nil {
+			return nil, err
+		}
+		sizes, err := measureDesign(forms, config)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+		}
+		return sizes, nil
+	}
+	if quote.Config.Scale == scale || quote.Config.Scale == 0 {
+		return quote.Sizes, nil
+	}
+
+	ratio := quote.Config.Scale / scale
+	sizes := make(map[string]domain.Size, len(quote.Sizes))
+	for id, size := range quote.Sizes {
+		strokeLengths := make([]float64, len(size.StrokeLengths))
+		for i, l := range size.StrokeLengths {
+			strokeLengths[i] = l * ratio
+		}
+		sizes[id] = domain.Size{
+			Length:        size.Length * ratio,
+			LengthPx:      size.LengthPx,
+			Height:        size.Height * ratio,
+			Width:         size.Width * ratio,
+			Strokes:       size.Strokes,
+			Corners:       size.Corners,
+			StrokeLengths: strokeLengths,
+			FeedPoints:    size.FeedPoints,
+			JumperLength:  size.JumperLength * ratio,
+			CableLength:   size.CableLength * ratio,
+			Standoffs:     size.Standoffs,
+			CableHoles:    size.CableHoles,
+		}
+	}
+	return sizes, nil
+}
+
+func percent(diff, total float64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return domain.Round(diff / total * 100)
+}
@@ -0,0 +1,67 @@
+package usecases
+
+import (
+	"testing"
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RepriceQuotes(t *testing.T) {
+	config := conf.Configuration{
+		Scale: 1000,
+		Pricing: conf.Pricing{
+			Silicones: []conf.Silicone{{SizeMm: 8, PricePerMeter: 1}},
+			LEDs:      []conf.LED{{Name: "couleur", PricePerMeter: 1}},
+			Plexis:    []conf.Plexi{{Name: "incolore", PricePerMeterSquare: 50}},
+		},
+	}
+	quotes := []domain.Quote{
+		{
+			ID:     "a",
+			Config: config,
+			Sizes:  map[string]domain.Size{"8MM": {Length: 2000, LengthPx: 2000}},
+			Prices: domain.Price{"8MM": {SiliconePrice: 2, LEDPrice: 2}},
+			Total:  4,
+		},
+		{
+			ID:     "b",
+			Config: conf.Configuration{Scale: 2000},
+			Sizes:  map[string]domain.Size{"DECOUPE": {Width: 1000, Height: 1000}},
+			Prices: domain.Price{"DECOUPE": {PlexiPrice: 50}},
+			Total:  50,
+		},
+		{
+			ID:  "c",
+			SVG: []byte(`<svg><g id="_x38_MM"><line x1="0" y1="0" x2="1000" y2="0"/></g></svg>`),
+		},
+		{
+			ID:       "d",
+			FileName: "listed.svg",
+			Total:    10,
+		},
+	}
+
+	newConfig := config.Clone()
+	newConfig.Silicones[0].PricePerMeter = 1.5
+
+	report, err := RepriceQuotes(quotes, newConfig)
+	require.NoError(t, err)
+	require.Len(t, report.Quotes, 3)
+
+	assert.Equal(t, 5.0, report.Quotes[0].NewTotal)
+	assert.Equal(t, 1.0, report.Quotes[0].Difference)
+	assert.Equal(t, 25.0, report.Quotes[0].DifferencePercent)
+	// scale went from 2000 to 1000 px per meter, the sheet is now twice as large.
+	assert.Equal(t, 200.0, report.Quotes[1].NewTotal)
+	assert.Equal(t, 2.5, report.Quotes[2].NewTotal)
+	assert.Equal(t, 0.0, report.Quotes[2].DifferencePercent)
+	assert.Equal(t, []SkippedQuote{{QuoteID: "d", FileName: "listed.svg", Reason: "quote has neither sizes nor svg file"}},
+		report.Skipped)
+
+	assert.Equal(t, 54.0, report.OldTotal)
+	assert.Equal(t, 207.5, report.NewTotal)
+	assert.Equal(t, 153.5, report.Difference)
+}
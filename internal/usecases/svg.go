@@ -1,26 +1,86 @@
 package usecases
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"theo303/neon-pricer/conf"
 	"theo303/neon-pricer/internal/domain"
+	"theo303/neon-pricer/internal/dxf"
 	"theo303/neon-pricer/internal/svg"
 )
 
-// ParseSVGFile parses an svg file and returns a map containing forms found in each groups.
-func ParseSVGFile(filepath string, groupID string) (map[string][]svg.Form, error) {
-	file, err := os.Open(filepath)
+// Formats of the design files.
+const (
+	DesignSVG = "svg"
+	DesignDXF = "dxf"
+)
+
+// DesignFormat returns the format of the design file, given by the extension of its name
+// or, without a known extension, by its content.
+func DesignFormat(fileName string, file []byte) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".svg":
+		return DesignSVG
+	case ".dxf":
+		return DesignDXF
+	}
+	if dxf.IsDXF(file) {
+		return DesignDXF
+	}
+	return DesignSVG
+}
+
+// ParseDesignFile parses an svg or DXF file and returns a map containing forms found in each groups,
+// the layers of DXF files being read as groups and their units converted to svg units with scale.
+func ParseDesignFile(path string, groupID string, scale float64) (map[string][]svg.Form, error) {
+	file, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("opening file: %w", err)
 	}
 
-	formsGroups, err := svg.RetrieveForms(file, groupID)
+	parsed, err := parseDesign(path, file, groupID, scale, svg.Limits{})
+	if err != nil {
+		return nil, fmt.Errorf("retrieving forms from design file: %w", err)
+	}
+
+	return parsed.Forms, nil
+}
+
+// parseDesign retrieves the forms of the svg or DXF design file.
+func parseDesign(fileName string, file []byte, groupID string, scale float64, limits svg.Limits) (svg.Parsed, error) {
+	var parsed svg.Parsed
+	var err error
+	if DesignFormat(fileName, file) == DesignDXF {
+		parsed, err = dxf.Parse(bytes.NewReader(file), groupID, scale, limits)
+	} else {
+		parsed, err = svg.Parse(bytes.NewReader(file), groupID, limits)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("retrieving forms from svg file: %w", err)
+		return svg.Parsed{}, err
 	}
+	normalizeBacking(parsed.Forms)
+	return parsed, nil
+}
 
-	return formsGroups, nil
+// normalizeBacking renames the group the backing is cut from to DECOUPE, designs naming it
+// in any case, the forms of several such groups being put together.
+func normalizeBacking(forms map[string][]svg.Form) {
+	ids := make([]string, 0, len(forms))
+	for id := range forms {
+		if id != "DECOUPE" && strings.EqualFold(id, "DECOUPE") {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		forms["DECOUPE"] = append(forms["DECOUPE"], forms[id]...)
+		delete(forms, id)
+	}
 }
 
 func GetLengths(formsGroups map[string][]svg.Form) (map[string]float64, error) {
@@ -67,6 +127,10 @@ func GetSizes(formsGroups map[string][]svg.Form, scale float64) (map[string]doma
 	if err != nil {
 		return nil, fmt.Errorf("computing bounds: %w", err)
 	}
+	polylines, err := GetPolylines(formsGroups)
+	if err != nil {
+		return nil, fmt.Errorf("computing strokes: %w", err)
+	}
 	sizes := make(map[string]domain.Size)
 	for id := range formsGroups {
 		length, ok := lengths[id]
@@ -77,12 +141,111 @@ func GetSizes(formsGroups map[string][]svg.Form, scale float64) (map[string]doma
 		if !ok {
 			return nil, fmt.Errorf("missing id %s in bounds map", id)
 		}
+		strokeLengths := make([]float64, len(polylines[id]))
+		for i, pl := range polylines[id] {
+			strokeLengths[i] = pl.Length() * 1000 / scale
+		}
 		sizes[id] = domain.Size{
-			Length:   length * 1000 / scale,
-			LengthPx: length,
-			Height:   bound.Height() * 1000 / scale,
-			Width:    bound.Width() * 1000 / scale,
+			Length:        length * 1000 / scale,
+			LengthPx:      length,
+			Height:        bound.Height() * 1000 / scale,
+			Width:         bound.Width() * 1000 / scale,
+			Strokes:       len(polylines[id]),
+			StrokeLengths: strokeLengths,
+		}
+	}
+	return sizes, nil
+}
+
+// backingBounds returns the bounds of the backing the design is mounted on, the DECOUPE group
+// cut from the plexi or, without it, the whole design with an empty group.
+func backingBounds(bounds map[string]svg.Bounds) (svg.Bounds, string, bool) {
+	if b, ok := bounds["DECOUPE"]; ok {
+		return b, "DECOUPE", true
+	}
+	ids := make([]string, 0, len(bounds))
+	for id := range bounds {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	var design svg.Bounds
+	for i, id := range ids {
+		if i == 0 {
+			design = bounds[id]
+		} else {
+			design = design.Expand(bounds[id])
+		}
+	}
+	return design, "", len(ids) > 0
+}
+
+// GetPolylines flattens the forms of each group into the strokes they are made of.
+func GetPolylines(formsGroups map[string][]svg.Form) (map[string][]svg.Polyline, error) {
+	polylines := make(map[string][]svg.Polyline)
+	for id, forms := range formsGroups {
+		for i, form := range forms {
+			pl, err := form.Polylines()
+			if err != nil {
+				return nil, fmt.Errorf("flattening form n %d of group %s: %w", i, id, err)
+			}
+			polylines[id] = append(polylines[id], pl...)
+		}
+	}
+	return polylines, nil
+}
+
+// measureDesign measures the groups of forms and plans how the sign is built: the sharp corners
+// of the strokes, their wiring and the holes drilled in the backing.
+func measureDesign(formsGroups map[string][]svg.Form, config conf.Configuration) (map[string]domain.Size, error) {
+	sizes, err := GetSizes(formsGroups, config.Scale)
+	if err != nil {
+		return nil, err
+	}
+	polylines, err := GetPolylines(formsGroups)
+	if err != nil {
+		return nil, fmt.Errorf("computing strokes: %w", err)
+	}
+	bounds, err := GetBounds(formsGroups)
+	if err != nil {
+		return nil, fmt.Errorf("computing bounds: %w", err)
+	}
+	countCorners(sizes, polylines, config.DesignRules.CornerAngleDeg)
+
+	wiring, err := PlanWiring(polylines, bounds, config)
+	if err != nil {
+		return nil, err
+	}
+	toMm := 1000 / config.Scale
+	for _, g := range wiring.Groups {
+		size := sizes[g.ID]
+		size.FeedPoints = len(g.Plan.Runs)
+		size.JumperLength = g.Plan.JumperLength * toMm
+		size.CableLength = g.CableLength * toMm
+		sizes[g.ID] = size
+	}
+
+	if backing, ok := sizes["DECOUPE"]; ok {
+		for _, hole := range PlanMounting(polylines, wiring, config) {
+			switch hole.Kind {
+			case HoleStandoff:
+				backing.Standoffs++
+			case HoleCable:
+				backing.CableHoles++
+			}
 		}
+		sizes["DECOUPE"] = backing
 	}
 	return sizes, nil
 }
+
+// countCorners sets the number of sharp corners of each group of sizes, if cornerAngleDeg is not 0.
+func countCorners(sizes map[string]domain.Size, polylines map[string][]svg.Polyline, cornerAngleDeg float64) {
+	if cornerAngleDeg == 0 {
+		return
+	}
+	for id, corners := range GetCorners(polylines, cornerAngleDeg) {
+		size := sizes[id]
+		size.Corners = corners
+		sizes[id] = size
+	}
+}
@@ -0,0 +1,133 @@
+package usecases
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+
+	"theo303/neon-pricer/conf"
+	"theo303/neon-pricer/internal/render"
+	"theo303/neon-pricer/internal/svg"
+)
+
+// GroupWiring is how the strokes of a group are fed.
+type GroupWiring struct {
+	ID   string
+	Plan FeedPlan
+	// Cables holds the length of the cable from the feed of each run to the power entry,
+	// routed horizontally then vertically behind the backing, in svg units.
+	Cables      []float64
+	CableLength float64
+}
+
+// WiringPlan is how the groups of a design are wired to the controller at the power entry.
+type WiringPlan struct {
+	Backing svg.Bounds
+	Entry   svg.Point
+	Groups  []GroupWiring
+}
+
+// PlanWiring plans the feeds of each group with a silicone and the cables linking them to the
+// power entry of the backing, the ends of strokes closer than the join tolerance being joined.
+func PlanWiring(polylines map[string][]svg.Polyline, bounds map[string]svg.Bounds, config conf.Configuration) (WiringPlan, error) {
+	backing, _, _ := backingBounds(bounds)
+	entry, err := powerEntry(backing, config.Wiring.PowerEntry)
+	if err != nil {
+		return WiringPlan{}, err
+	}
+	ids := make([]string, 0, len(polylines))
+	for id := range polylines {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	plan := WiringPlan{Backing: backing, Entry: entry}
+	for _, id := range ids {
+		siliconeSize, err := getSiliconeSize(strings.ToUpper(id))
+		if err != nil {
+			return WiringPlan{}, fmt.Errorf("retrieving silicone size: %w", err)
+		}
+		if siliconeSize == 0 {
+			continue
+		}
+		group := GroupWiring{ID: id, Plan: PlanFeeds(polylines[id], config.DesignRules.JoinToleranceMm*config.Scale/1000)}
+		for _, run := range group.Plan.Runs {
+			cable := math.Abs(run.Feed.X-entry.X) + math.Abs(run.Feed.Y-entry.Y)
+			group.Cables = append(group.Cables, cable)
+			group.CableLength += cable
+		}
+		plan.Groups = append(plan.Groups, group)
+	}
+	return plan, nil
+}
+
+// powerEntry returns the point of the backing where the power enters.
+func powerEntry(backing svg.Bounds, entry string) (svg.Point, error) {
+	lo, hi := backing.Min(), backing.Max()
+	center := svg.Point{X: (lo.X + hi.X) / 2, Y: (lo.Y + hi.Y) / 2}
+	switch entry {
+	case conf.PowerEntryBottom, "":
+		return svg.Point{X: center.X, Y: hi.Y}, nil
+	case conf.PowerEntryTop:
+		return svg.Point{X: center.X, Y: lo.Y}, nil
+	case conf.PowerEntryLeft:
+		return svg.Point{X: lo.X, Y: center.Y}, nil
+	case conf.PowerEntryRight:
+		return svg.Point{X: hi.X, Y: center.Y}, nil
+	case conf.PowerEntryBottomLeft:
+		return svg.Point{X: lo.X, Y: hi.Y}, nil
+	case conf.PowerEntryBottomRight:
+		return hi, nil
+	case conf.PowerEntryTopLeft:
+		return lo, nil
+	case conf.PowerEntryTopRight:
+		return svg.Point{X: hi.X, Y: lo.Y}, nil
+	case conf.PowerEntryCenter:
+		return center, nil
+	}
+	return svg.Point{}, fmt.Errorf("unknown power entry %q, expected one of %s", entry, strings.Join(conf.PowerEntries, ", "))
+}
+
+// WriteWiring writes the wiring diagram of the design file: its backing, the runs of strokes of each
+// group with their feed points and jumpers, and the cables to the power entry. It returns the plan drawn.
+func WriteWiring(w io.Writer, fileName string, file []byte, config conf.Configuration) (WiringPlan, error) {
+	forms, err := retrieveDesign(fileName, file, config, svg.Limits{})
+	if err != nil {
+		return WiringPlan{}, err
+	}
+	polylines, err := GetPolylines(forms)
+	if err != nil {
+		return WiringPlan{}, fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+	}
+	bounds, err := GetBounds(forms)
+	if err != nil {
+		return WiringPlan{}, fmt.Errorf("%w: %w", ErrInvalidDesign, err)
+	}
+	plan, err := PlanWiring(polylines, bounds, config)
+	if err != nil {
+		return WiringPlan{}, err
+	}
+
+	diagram := render.WiringDiagram{Backing: plan.Backing, Entry: plan.Entry}
+	for _, g := range plan.Groups {
+		group := render.WiredGroup{
+			ID:             g.ID,
+			CableLengthMm:  g.CableLength * 1000 / config.Scale,
+			JumperLengthMm: g.Plan.JumperLength * 1000 / config.Scale,
+		}
+		for _, run := range g.Plan.Runs {
+			wired := render.WiredRun{Feed: run.Feed, End: run.End}
+			for _, s := range run.Strokes {
+				wired.Polylines = append(wired.Polylines, polylines[g.ID][s])
+			}
+			group.Runs = append(group.Runs, wired)
+		}
+		diagram.Groups = append(diagram.Groups, group)
+	}
+	if err := render.Wiring(w, diagram); err != nil {
+		return WiringPlan{}, fmt.Errorf("drawing wiring diagram: %w", err)
+	}
+	return plan, nil
+}
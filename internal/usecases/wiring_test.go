@@ -0,0 +1,82 @@
+package usecases
+
+import (
+	"bytes"
+	"testing"
+
+	"theo303/neon-pricer/internal/svg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PlanWiring(t *testing.T) {
+	backing := svg.Polyline{{X: -10, Y: -10}, {X: 60, Y: -10}, {X: 60, Y: 20}, {X: -10, Y: 20}, {X: -10, Y: -10}}
+	polylines := map[string][]svg.Polyline{
+		"6MM":     {{{X: 0, Y: 0}, {X: 10, Y: 0}}, {{X: 40, Y: 0}, {X: 50, Y: 0}}},
+		"DECOUPE": {backing},
+	}
+	bounds := map[string]svg.Bounds{
+		"6MM":     polylines["6MM"][0].Bounds().Expand(polylines["6MM"][1].Bounds()),
+		"DECOUPE": backing.Bounds(),
+	}
+	tests := map[string]struct {
+		entry      string
+		wantEntry  svg.Point
+		wantCables []float64
+		wantErr    bool
+	}{
+		"bottom centre by default": {
+			wantEntry:  svg.Point{X: 25, Y: 20},
+			wantCables: []float64{45, 35},
+		},
+		"top left": {
+			entry:      "top-left",
+			wantEntry:  svg.Point{X: -10, Y: -10},
+			wantCables: []float64{20, 60},
+		},
+		"unknown entry": {
+			entry:   "middle",
+			wantErr: true,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := testConfiguration()
+			config.Scale = 1000
+			config.Wiring.PowerEntry = tt.entry
+			plan, err := PlanWiring(polylines, bounds, config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantEntry, plan.Entry)
+			require.Len(t, plan.Groups, 1)
+			g := plan.Groups[0]
+			assert.Equal(t, "6MM", g.ID)
+			assert.Len(t, g.Plan.Runs, 2)
+			assert.Equal(t, 30.0, g.Plan.JumperLength)
+			assert.Equal(t, tt.wantCables, g.Cables)
+			assert.Equal(t, 80.0, g.CableLength)
+		})
+	}
+}
+
+func Test_WriteWiring(t *testing.T) {
+	file := []byte(`<svg><g id="6MM"><line x1="0" y1="0" x2="100" y2="0"/></g>` +
+		`<g id="DECOUPE"><rect x="-10" y="-10" width="120" height="30"/></g></svg>`)
+	config := testConfiguration()
+	config.Scale = 1000
+
+	var buf bytes.Buffer
+	plan, err := WriteWiring(&buf, "", file, config)
+	require.NoError(t, err)
+	require.Len(t, plan.Groups, 1)
+	assert.Equal(t, []float64{70}, plan.Groups[0].Cables)
+	assert.Contains(t, buf.String(), `<path d="M0 0 H50 V20"`)
+	assert.Contains(t, buf.String(), "6MM: 1 feed points, 70 mm of cable, 0 mm of jumpers")
+
+	_, err = WriteWiring(&buf, "", []byte("<svg"), config)
+	assert.ErrorIs(t, err, ErrInvalidDesign)
+}
@@ -0,0 +1,8 @@
+// Package templates embeds the html templates served by the server,
+// so the binary does not depend on its working directory.
+package templates
+
+import "embed"
+
+//go:embed *.html
+var FS embed.FS